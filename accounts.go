@@ -0,0 +1,441 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultGithubHost   = "github.com"
+	accountsFileVersion = 1
+)
+
+// githubAccount is a signed-in GitHub identity. Remembered accounts are persisted in
+// accounts.json together with their token envelope; the others live for the session only.
+// ID and Host never change once the account is listed; every other field is guarded by a.mu
+// because the token watcher, the device-flow poller and the UI all update accounts.
+type githubAccount struct {
+	ID    string                `json:"id"` // host + "/" + lower-case login
+	Host  string                `json:"host"`
+	Label string                `json:"label,omitempty"`
+	User  *GithubUserProfile    `json:"user,omitempty"`
+	Token *tokenEnvelope        `json:"token,omitempty"`
+	App   *githubAppCredentials `json:"app,omitempty"` // set for GitHub App installations
+
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // from github-authentication-token-expiration
+	Expired   bool       `json:"expired,omitempty"`   // GitHub answered 401; sign in again to renew
+
+	token           string // decrypted token; empty while the envelope is locked
+	permissions     *TokenPermissions
+	legacyPlaintext bool // still stored in the plaintext token file of older versions
+	expiryWarned    bool
+	unverified      string // why the cached profile couldn't be verified; empty once it is
+	avatarData      string // cached avatar as a data URL, shown while unverified
+
+	installationExpiry time.Time // when the current installation token runs out
+	remembered         bool      // GitHub App accounts have no token to store; this keeps them
+}
+
+type accountsFile struct {
+	Version         int              `json:"version"`
+	ActiveAccountID string           `json:"activeAccountId,omitempty"`
+	Accounts        []*githubAccount `json:"accounts"`
+}
+
+// GithubAccount is the view of an account handed to the frontend.
+type GithubAccount struct {
+	ID               string             `json:"id"`
+	Host             string             `json:"host"`
+	Label            string             `json:"label"`
+	User             *GithubUserProfile `json:"user,omitempty"`
+	Active           bool               `json:"active"`
+	Remembered       bool               `json:"remembered"`
+	Locked           bool               `json:"locked"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty"`
+	Expired          bool               `json:"expired"`
+	Unverified       bool               `json:"unverified"`
+	UnverifiedReason string             `json:"unverifiedReason,omitempty"` // offline, sso-required, server-error or error
+}
+
+// githubSession is the active account's login copied out under a.mu, so a GitHub call can
+// use it while background goroutines update the accounts.
+type githubSession struct {
+	AccountID   string
+	Host        string
+	Token       string
+	User        *GithubUserProfile
+	Permissions *TokenPermissions
+	App         *githubAppCredentials
+	Expired     bool
+	Unverified  bool
+}
+
+// signedIn reports whether there is a usable token and profile.
+func (s githubSession) signedIn() bool {
+	return s.Token != "" && s.User != nil
+}
+
+type RenameGithubAccountRequest struct {
+	ID    string `json:"id"`
+	Label string `json:"label"`
+}
+
+func githubAccountID(host, login string) string {
+	return host + "/" + strings.ToLower(login)
+}
+
+func (acc *githubAccount) keyringAccount() string {
+	return keyringTokenAccount + ":" + acc.ID
+}
+
+func (acc *githubAccount) locked() bool {
+	return acc.token == "" && acc.Token != nil && acc.Token.Backend == tokenBackendPassphrase
+}
+
+// persisted reports whether the account is written to accounts.json.
+func (acc *githubAccount) persisted() bool {
+	return acc.Token != nil || (acc.isApp() && acc.remembered)
+}
+
+func (acc *githubAccount) view(activeID string) GithubAccount {
+	label := acc.Label
+	if label == "" && acc.User != nil {
+		label = acc.User.Login
+		if acc.Host != defaultGithubHost {
+			label += "@" + acc.Host
+		}
+	}
+	return GithubAccount{
+		ID:               acc.ID,
+		Host:             acc.Host,
+		Label:            label,
+		User:             acc.profile(),
+		Active:           acc.ID == activeID,
+		Remembered:       acc.persisted(),
+		Locked:           acc.locked(),
+		ExpiresAt:        acc.ExpiresAt,
+		Expired:          acc.Expired,
+		Unverified:       acc.unverified != "",
+		UnverifiedReason: acc.unverified,
+	}
+}
+
+// normaliseGithubHost accepts "github.com", "ghe.example.com" or a URL of either and
+// returns the bare host name. An empty host means github.com.
+func normaliseGithubHost(host string) (string, error) {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if host == "" {
+		return defaultGithubHost, nil
+	}
+	if !strings.Contains(host, "://") {
+		host = "https://" + host
+	}
+	parsed, err := url.Parse(host)
+	if err != nil || parsed.Host == "" || strings.Trim(parsed.Path, "/") != "" {
+		return "", fmt.Errorf("invalid GitHub host %q", host)
+	}
+	if parsed.Host == "api.github.com" || parsed.Host == "www.github.com" {
+		return defaultGithubHost, nil
+	}
+	return parsed.Host, nil
+}
+
+// apiBaseForHost returns the REST API root of github.com or a GitHub Enterprise Server host.
+func (a *App) apiBaseForHost(host string) string {
+	if host == "" || host == defaultGithubHost {
+		if a.githubAPIBase != "" {
+			return strings.TrimRight(a.githubAPIBase, "/")
+		}
+		return defaultGithubAPIBase
+	}
+	return "https://" + host + "/api/v3"
+}
+
+func (a *App) webBaseForHost(host string) string {
+	if host == "" || host == defaultGithubHost {
+		if a.githubWebBase != "" {
+			return strings.TrimRight(a.githubWebBase, "/")
+		}
+		return defaultGithubWebBase
+	}
+	return "https://" + host
+}
+
+// ListGithubAccounts returns every known account, remembered or not.
+func (a *App) ListGithubAccounts() []GithubAccount {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	accounts := make([]GithubAccount, 0, len(a.accounts))
+	for _, acc := range a.accounts {
+		accounts = append(accounts, acc.view(a.activeAccountID))
+	}
+	return accounts
+}
+
+// SwitchGithubAccount makes another account drive GenerateRepo and the repository tools.
+func (a *App) SwitchGithubAccount(id string) (*GithubLoginStatus, error) {
+	a.mu.Lock()
+	acc := a.findAccount(id)
+	locked := acc != nil && acc.locked()
+	a.mu.Unlock()
+	if acc == nil {
+		return nil, fmt.Errorf("unknown GitHub account %q", id)
+	}
+	if locked {
+		return nil, fmt.Errorf("%w; unlock it first", errTokenLocked)
+	}
+
+	a.activateAccount(acc)
+	a.audit(auditActionAccountSwitch, "", nil, nil)
+	if err := a.saveAccounts(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+	}
+	return a.GetGithubLoginStatus(), nil
+}
+
+// RenameGithubAccount sets the label shown for an account; an empty label restores the default.
+func (a *App) RenameGithubAccount(req RenameGithubAccountRequest) error {
+	a.mu.Lock()
+	acc := a.findAccount(req.ID)
+	if acc == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("unknown GitHub account %q", req.ID)
+	}
+	acc.Label = strings.TrimSpace(req.Label)
+	active := acc.ID == a.activeAccountID
+	a.mu.Unlock()
+
+	if active {
+		a.emitGithubAuthChanged()
+	}
+	return a.saveAccounts()
+}
+
+// RemoveGithubAccount signs an account out and forgets its stored token.
+func (a *App) RemoveGithubAccount(id string) error {
+	a.mu.Lock()
+	acc := a.findAccount(id)
+	if acc == nil {
+		a.mu.Unlock()
+		return fmt.Errorf("unknown GitHub account %q", id)
+	}
+	a.dropAccount(acc)
+	legacyPlaintext := acc.legacyPlaintext
+	wasActive := id == a.activeAccountID
+	var next *githubAccount
+	for _, candidate := range a.accounts {
+		if !candidate.locked() {
+			next = candidate
+			break
+		}
+	}
+	a.mu.Unlock()
+
+	if err := a.forgetAccountToken(acc); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to clear saved GitHub token: %v", err)
+	}
+	if legacyPlaintext {
+		if err := a.removeLegacyTokenFile(); err != nil && a.ctx != nil {
+			runtime.LogWarningf(a.ctx, "failed to clear saved GitHub token: %v", err)
+		}
+	}
+	removeCachedAvatar(acc.ID)
+	a.auditAs(acc.ID, auditActionAccountRemove, "", nil, nil)
+
+	if wasActive {
+		a.activateAccount(next)
+	}
+	return a.saveAccounts()
+}
+
+// signInWithToken adds or refreshes the account the token belongs to, makes it active and
+// remembers it if asked to.
+func (a *App) signInWithToken(host, token string, remember bool, passphrase string) (*GithubAuthResponse, error) {
+	user, header, err := a.fetchGithubUser(host, token)
+	if err != nil {
+		a.auditAs("", auditActionLogin, "", err, map[string]string{"host": host})
+		return nil, err
+	}
+	permissions := a.tokenPermissions(host, token, header)
+
+	a.mu.Lock()
+	acc := a.upsertAccount(host, user)
+	acc.token = token
+	acc.permissions = permissions
+	acc.ExpiresAt = nil
+	acc.Expired = false
+	acc.expiryWarned = false
+	acc.unverified = ""
+	acc.avatarData = ""
+	a.mu.Unlock()
+	a.activateAccount(acc)
+	go a.cacheAvatar(acc.ID, user.AvatarURL)
+
+	remembered := false
+	if remember {
+		if err := a.sealAccountToken(acc, passphrase); err != nil {
+			if a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "failed to store GitHub token: %v", err)
+			}
+		} else {
+			remembered = true
+		}
+	} else if err := a.forgetAccountToken(acc); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to clear saved GitHub token: %v", err)
+	}
+	if err := a.saveAccounts(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+	}
+	a.audit(auditActionLogin, "", nil, map[string]string{"host": host, "remembered": fmt.Sprint(remembered)})
+
+	return &GithubAuthResponse{
+		AccountID:  acc.ID,
+		User:       cloneGithubUser(user),
+		Remembered: remembered,
+	}, nil
+}
+
+// upsertAccount, dropAccount, findAccount and activeAccount expect a.mu to be held.
+
+func (a *App) upsertAccount(host string, user *GithubUserProfile) *githubAccount {
+	id := githubAccountID(host, user.Login)
+	acc := a.findAccount(id)
+	if acc == nil {
+		acc = &githubAccount{ID: id, Host: host}
+		a.accounts = append(a.accounts, acc)
+	}
+	acc.User = user
+	return acc
+}
+
+func (a *App) dropAccount(acc *githubAccount) {
+	for i, candidate := range a.accounts {
+		if candidate == acc {
+			a.accounts = append(a.accounts[:i], a.accounts[i+1:]...)
+			return
+		}
+	}
+}
+
+func (a *App) findAccount(id string) *githubAccount {
+	for _, acc := range a.accounts {
+		if acc.ID == id {
+			return acc
+		}
+	}
+	return nil
+}
+
+func (a *App) activeAccount() *githubAccount {
+	return a.findAccount(a.activeAccountID)
+}
+
+// addAccount lists an account restored from disk.
+func (a *App) addAccount(acc *githubAccount) {
+	a.mu.Lock()
+	a.accounts = append(a.accounts, acc)
+	a.mu.Unlock()
+}
+
+// session copies the active account's login out under a.mu. Without an active account
+// it is empty apart from the default host.
+func (a *App) session() githubSession {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	acc := a.activeAccount()
+	if acc == nil {
+		return githubSession{Host: defaultGithubHost}
+	}
+	session := githubSession{
+		AccountID:   acc.ID,
+		Host:        acc.Host,
+		Token:       acc.token,
+		User:        acc.profile(),
+		Permissions: cloneTokenPermissions(acc.permissions),
+		Expired:     acc.Expired,
+		Unverified:  acc.unverified != "",
+	}
+	if acc.App != nil {
+		creds := *acc.App
+		session.App = &creds
+	}
+	return session
+}
+
+// activateAccount makes the account the one GitHub calls are made with. A nil account signs out.
+func (a *App) activateAccount(acc *githubAccount) {
+	a.mu.Lock()
+	a.activeAccountID = ""
+	if acc != nil {
+		a.activeAccountID = acc.ID
+	}
+	a.mu.Unlock()
+	a.emitGithubAuthChanged()
+}
+
+func accountsPath() (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "accounts.json"), nil
+}
+
+// saveAccounts persists the remembered accounts and which one is active.
+func (a *App) saveAccounts() error {
+	path, err := accountsPath()
+	if err != nil {
+		return err
+	}
+
+	// Held while writing too, so concurrent saves can't interleave.
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	file := accountsFile{Version: accountsFileVersion, Accounts: []*githubAccount{}}
+	for _, acc := range a.accounts {
+		if !acc.persisted() {
+			continue
+		}
+		file.Accounts = append(file.Accounts, acc)
+		if acc.ID == a.activeAccountID {
+			file.ActiveAccountID = acc.ID
+		}
+	}
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode GitHub accounts: %w", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func (a *App) readAccountsFile() (*accountsFile, error) {
+	path, err := accountsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &accountsFile{Version: accountsFileVersion}, nil
+		}
+		return nil, err
+	}
+
+	var file accountsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("decode GitHub accounts: %w", err)
+	}
+	if file.Version != accountsFileVersion {
+		return nil, fmt.Errorf("unsupported GitHub accounts file version %d", file.Version)
+	}
+	return &file, nil
+}
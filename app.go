@@ -7,12 +7,15 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
 	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/wailsapp/wails/v2/pkg/runtime"
@@ -20,17 +23,29 @@ import (
 
 // App struct
 type App struct {
-	ctx          context.Context
-	repoBasePath string
-	gitPath      string // custom git path; empty means use the system default
-	githubToken  string
-	githubUser   *GithubUserProfile
+	ctx           context.Context
+	repoBasePath  string
+	gitPath       string // custom git path; empty means use the system default
+	githubAPIBase string // REST API root; overridable so tests can point at a fake server
+	githubWebBase string // web root serving the OAuth device flow endpoints
+
+	network atomic.Pointer[networkState] // nil until network settings are applied
+
+	// mu guards the accounts, which one is active and the device login in progress.
+	// Use session() to read the active login.
+	mu                sync.Mutex
+	accounts          []*githubAccount
+	activeAccountID   string
+	cancelDeviceLogin context.CancelFunc
+	deviceLoginID     uint64 // tells the poll that owns cancelDeviceLogin from ones it replaced
 }
 
 // NewApp creates a new App application struct
 func NewApp() *App {
 	return &App{
-		repoBasePath: filepath.Join(os.TempDir(), "green-wall"),
+		repoBasePath:  filepath.Join(os.TempDir(), "green-wall"),
+		githubAPIBase: defaultGithubAPIBase,
+		githubWebBase: defaultGithubWebBase,
 	}
 }
 
@@ -38,9 +53,18 @@ func NewApp() *App {
 // so we can call the runtime methods
 func (a *App) startup(ctx context.Context) {
 	a.ctx = ctx
-	if err := a.loadRememberedGithubToken(); err != nil {
+	if err := a.loadAppSettings(); err != nil {
+		runtime.LogWarningf(ctx, "Failed to apply network settings: %v", err)
+	}
+	if err := a.loadGithubAccounts(); err != nil {
 		runtime.LogWarningf(ctx, "Failed to restore GitHub login: %v", err)
 	}
+	go a.watchGithubTokens(ctx)
+	if pending, err := a.loadPendingGeneration(); err != nil {
+		runtime.LogWarningf(ctx, "Failed to read pending generation: %v", err)
+	} else if pending != nil {
+		runtime.LogInfof(ctx, "Generation of %s/%s is pending: %s", pending.Owner, pending.RepoName, pending.LastError)
+	}
 }
 
 type ContributionDay struct {
@@ -55,6 +79,12 @@ type GenerateRepoRequest struct {
 	RepoName       string             `json:"repoName"`
 	Contributions  []ContributionDay  `json:"contributions"`
 	RemoteRepo     *RemoteRepoOptions `json:"remoteRepo,omitempty"`
+	// Timezone is the IANA zone the commits are dated in, as saved in a design; empty is UTC.
+	Timezone string `json:"timezone,omitempty"`
+	// Identity is the commit author saved in a design; what it sets wins over the account's.
+	Identity *DesignIdentity `json:"identity,omitempty"`
+	// AllowUnattributedEmail skips the check that GitHub will count commits made with the email.
+	AllowUnattributedEmail bool `json:"allowUnattributedEmail,omitempty"`
 }
 
 type GenerateRepoResponse struct {
@@ -68,6 +98,8 @@ var githubRepoNameValidator = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,100}$`)
 
 const githubAuthChangedEvent = "github:auth-changed"
 
+const defaultGithubAPIBase = "https://api.github.com"
+
 type CheckGitInstalledResponse struct {
 	Installed bool   `json:"installed"`
 	Version   string `json:"version"`
@@ -84,18 +116,29 @@ type SetGitPathResponse struct {
 }
 
 type RemoteRepoOptions struct {
-	Enabled     bool   `json:"enabled"`
-	Name        string `json:"name"`
-	Private     bool   `json:"private"`
-	Description string `json:"description"`
+	Enabled          bool     `json:"enabled"`
+	Name             string   `json:"name"`
+	Private          bool     `json:"private"`
+	Description      string   `json:"description"`
+	Topics           []string `json:"topics,omitempty"`
+	Homepage         string   `json:"homepage,omitempty"`
+	DefaultBranch    string   `json:"defaultBranch,omitempty"`
+	LicenseTemplate  string   `json:"licenseTemplate,omitempty"` // e.g. "mit" or "apache-2.0"
+	DisableIssues    bool     `json:"disableIssues,omitempty"`
+	DisableWiki      bool     `json:"disableWiki,omitempty"`
+	DisableProjects  bool     `json:"disableProjects,omitempty"`
+	ArchiveAfterPush bool     `json:"archiveAfterPush,omitempty"`
 }
 
 type GithubAuthRequest struct {
-	Token    string `json:"token"`
-	Remember bool   `json:"remember"`
+	Host       string `json:"host,omitempty"` // GitHub Enterprise Server host; empty means github.com
+	Token      string `json:"token"`
+	Remember   bool   `json:"remember"`
+	Passphrase string `json:"passphrase,omitempty"` // encrypts the remembered token; empty uses the OS secret service
 }
 
 type GithubUserProfile struct {
+	ID        int64  `json:"id,omitempty"`
 	Login     string `json:"login"`
 	Name      string `json:"name"`
 	Email     string `json:"email"`
@@ -103,13 +146,20 @@ type GithubUserProfile struct {
 }
 
 type GithubAuthResponse struct {
+	AccountID  string             `json:"accountId"`
 	User       *GithubUserProfile `json:"user"`
 	Remembered bool               `json:"remembered"`
 }
 
 type GithubLoginStatus struct {
-	Authenticated bool               `json:"authenticated"`
-	User          *GithubUserProfile `json:"user,omitempty"`
+	Authenticated    bool               `json:"authenticated"`
+	AccountID        string             `json:"accountId,omitempty"`
+	User             *GithubUserProfile `json:"user,omitempty"`
+	Permissions      *TokenPermissions  `json:"permissions,omitempty"`
+	ExpiresAt        *time.Time         `json:"expiresAt,omitempty"`
+	Expired          bool               `json:"expired,omitempty"`
+	Unverified       bool               `json:"unverified,omitempty"`       // cached profile GitHub hasn't confirmed yet
+	UnverifiedReason string             `json:"unverifiedReason,omitempty"` // as in GithubAccount
 }
 
 type githubEmailEntry struct {
@@ -120,11 +170,16 @@ type githubEmailEntry struct {
 }
 
 type githubRepository struct {
-	Name     string `json:"name"`
-	FullName string `json:"full_name"`
-	HTMLURL  string `json:"html_url"`
-	CloneURL string `json:"clone_url"`
-	Owner    struct {
+	Name      string    `json:"name"`
+	FullName  string    `json:"full_name"`
+	HTMLURL   string    `json:"html_url"`
+	CloneURL  string    `json:"clone_url"`
+	Private   bool      `json:"private"`
+	Archived  bool      `json:"archived"`
+	Topics    []string  `json:"topics"`
+	CreatedAt time.Time `json:"created_at"`
+	PushedAt  time.Time `json:"pushed_at"`
+	Owner     struct {
 		Login string `json:"login"`
 	} `json:"owner"`
 }
@@ -217,71 +272,82 @@ func (a *App) GenerateRepo(req GenerateRepoRequest) (*GenerateRepoResponse, erro
 	if totalRequestedCommits == 0 {
 		return nil, fmt.Errorf("no commits to generate")
 	}
+	location, err := commitLocation(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
 
+	// The remote steps all use the account signed in now, even if the user switches meanwhile.
+	session := a.session()
+	var auth githubAuth
 	var remoteOptions *RemoteRepoOptions
 	if req.RemoteRepo != nil && req.RemoteRepo.Enabled {
-		trimmedName := strings.TrimSpace(req.RemoteRepo.Name)
-		if trimmedName == "" {
-			return nil, fmt.Errorf("remote repository name cannot be empty")
+		if !session.signedIn() {
+			return nil, fmt.Errorf("GitHub login is required to create a remote repository")
 		}
-		if !githubRepoNameValidator.MatchString(trimmedName) {
-			return nil, fmt.Errorf("remote repository name may only contain letters, numbers, '.', '_' or '-'")
+		if session.Expired {
+			return nil, fmt.Errorf("the GitHub token of %s has expired; sign in again", session.AccountID)
 		}
-		if a.githubToken == "" || a.githubUser == nil {
-			return nil, fmt.Errorf("GitHub login is required to create a remote repository")
+		if err := session.Permissions.checkRemoteGeneration(req.RemoteRepo.Private); err != nil {
+			return nil, err
 		}
-		remoteOptions = &RemoteRepoOptions{
-			Enabled:     true,
-			Name:        trimmedName,
-			Private:     req.RemoteRepo.Private,
-			Description: strings.TrimSpace(req.RemoteRepo.Description),
+		if auth, err = a.accountGithubAuth(session.AccountID); err != nil {
+			return nil, err
+		}
+		if pending, err := a.loadPendingGeneration(); err != nil {
+			return nil, err
+		} else if pending != nil {
+			return nil, fmt.Errorf("the generation of %s/%s is still pending; resume or discard it first", pending.Owner, pending.RepoName)
+		}
+		normalised, err := requestedRemoteRepo(req, session.User.Login)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	username := strings.TrimSpace(req.GithubUsername)
-	if a.githubUser != nil && strings.TrimSpace(a.githubUser.Login) != "" {
-		username = strings.TrimSpace(a.githubUser.Login)
-	}
-	if username == "" {
-		username = "greenwall"
+		// Pre-flight: catch name collisions before the local history is built.
+		available, err := a.githubRepoNameAvailable(auth, session.User.Login, normalised.Name)
+		if err != nil {
+			return nil, err
+		}
+		if !available {
+			suggestions, err := a.suggestRepoNames(auth, session.User.Login, normalised.Name, req.Year)
+			if err != nil || len(suggestions) == 0 {
+				return nil, fmt.Errorf("repository %s/%s already exists", session.User.Login, normalised.Name)
+			}
+			return nil, fmt.Errorf("repository %s/%s already exists; available alternatives: %s", session.User.Login, normalised.Name, strings.Join(suggestions, ", "))
+		}
+		remoteOptions = normalised
 	}
-	email := strings.TrimSpace(req.GithubEmail)
-	if email == "" && a.githubUser != nil && strings.TrimSpace(a.githubUser.Email) != "" {
-		email = strings.TrimSpace(a.githubUser.Email)
+
+	username, email, err := a.commitIdentity(req)
+	if err != nil {
+		return nil, err
 	}
-	if email == "" {
-		email = fmt.Sprintf("%s@users.noreply.github.com", username)
+
+	history, err := a.newContributionHistory(req, remoteOptions, session, auth, username, email, location)
+	if err != nil {
+		return nil, err
 	}
+	repoName, branchName := history.RepoName, history.Branch
 
 	if err := os.MkdirAll(a.repoBasePath, 0o755); err != nil {
 		return nil, fmt.Errorf("create repo base directory: %w", err)
 	}
 
-	repoName := strings.TrimSpace(req.RepoName)
-	if remoteOptions != nil {
-		repoName = remoteOptions.Name
-	}
-	if repoName == "" {
-		repoName = username
-		if req.Year > 0 {
-			repoName = fmt.Sprintf("%s-%d", repoName, req.Year)
-		}
-	}
-	if remoteOptions == nil {
-		repoName = sanitiseRepoName(repoName)
-		if repoName == "" {
-			repoName = "contributions"
-		}
-	}
-
 	repoPath, err := os.MkdirTemp(a.repoBasePath, repoName+"-")
 	if err != nil {
 		return nil, fmt.Errorf("create repo directory: %w", err)
 	}
+	// Roll the local directory back unless generation succeeds or a pending
+	// generation takes ownership of it.
+	keepRepoDir := false
+	defer func() {
+		if !keepRepoDir {
+			_ = os.RemoveAll(repoPath)
+		}
+	}()
 
-	readmePath := filepath.Join(repoPath, "README.md")
-	readmeContent := fmt.Sprintf("# %s\n\nGenerated with https://github.com/zmrlft/GreenWall.\n", repoName)
-	if err := os.WriteFile(readmePath, []byte(readmeContent), 0o644); err != nil {
+	if err := os.WriteFile(filepath.Join(repoPath, "README.md"), []byte(history.readme()), 0o644); err != nil {
 		return nil, fmt.Errorf("write README: %w", err)
 	}
 
@@ -303,31 +369,285 @@ func (a *App) GenerateRepo(req GenerateRepoRequest) (*GenerateRepoResponse, erro
 	_ = a.runGitCommand(repoPath, "config", "core.fsyncObjectFiles", "false")
 	_ = a.runGitCommand(repoPath, "config", "credential.helper", "") // ensure global helpers can't override askpass
 
+	stream, totalCommits, err := buildFastImportStream(history)
+	if err != nil {
+		return nil, err
+	}
+
+	// Feed stream to fast-import
+	if totalCommits > 0 {
+		if err := a.runGitFastImport(repoPath, stream); err != nil {
+			err = fmt.Errorf("fast-import failed: %w", err)
+			a.audit(auditActionGenerate, repoPath, err, nil)
+			return nil, err
+		}
+		// Update working tree to the generated branch for user convenience
+		_ = a.runGitCommand(repoPath, "checkout", "-f", branchName)
+	}
+	a.audit(auditActionGenerate, repoPath, nil, map[string]string{"commits": fmt.Sprint(totalCommits), "year": fmt.Sprint(req.Year)})
+
+	var remoteURL string
+	if remoteOptions != nil {
+		// Journal the generation before the remote repository exists, so a crash or a
+		// failed write can never leave a repository behind that the app doesn't know about.
+		now := time.Now()
+		pending := &PendingGeneration{
+			AccountID:   session.AccountID,
+			RepoPath:    repoPath,
+			CommitCount: totalCommits,
+			Owner:       session.User.Login,
+			RepoName:    remoteOptions.Name,
+			Branch:      branchName,
+			Topics:      remoteOptions.Topics,
+			Archive:     remoteOptions.ArchiveAfterPush,
+			Steps:       []string{generationStepLocalRepo, generationStepHistory, generationStepRemoteRequested},
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := a.savePendingGeneration(pending); err != nil {
+			return nil, fmt.Errorf("record pending generation: %w", err)
+		}
+		keepRepoDir = true
+
+		createdRepo, err := a.createGithubRepository(auth, session, remoteOptions)
+		if err != nil {
+			a.audit(auditActionRepoCreate, session.User.Login+"/"+remoteOptions.Name, err, nil)
+			if clearErr := a.clearPendingGeneration(); clearErr != nil && a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "failed to clear pending generation: %v", clearErr)
+			}
+			keepRepoDir = false
+			return nil, err
+		}
+		ownerLogin := createdRepo.Owner.Login
+		if ownerLogin == "" {
+			ownerLogin = session.User.Login
+		}
+		a.audit(auditActionRepoCreate, ownerLogin+"/"+createdRepo.Name, nil, map[string]string{"private": fmt.Sprint(remoteOptions.Private)})
+
+		// From here on the remote repository exists, so failures are journaled
+		// instead of rolled back and the user decides whether to resume or discard.
+		pending.Owner = ownerLogin
+		if createdRepo.Name != "" {
+			pending.RepoName = createdRepo.Name
+		}
+		pending.CloneURL = strings.TrimSpace(createdRepo.CloneURL)
+		pending.HTMLURL = createdRepo.HTMLURL
+		pending.recordStep(generationStepRemoteRepo)
+		pending.UpdatedAt = time.Now()
+		if pending.CloneURL == "" {
+			pending.LastError = "GitHub did not return a clone URL for the new repository"
+		}
+		if err := a.savePendingGeneration(pending); err != nil {
+			return nil, fmt.Errorf("%w: %s/%s was created but recording it failed: %v", errGenerationPending, pending.Owner, pending.RepoName, err)
+		}
+		if pending.CloneURL == "" {
+			return nil, fmt.Errorf("%w: %s", errGenerationPending, pending.LastError)
+		}
+
+		remoteURL, err = a.pushPendingGeneration(pending, auth)
+		if err != nil {
+			return nil, err
+		}
+		if remoteURL != "" && a.ctx != nil {
+			runtime.BrowserOpenURL(a.ctx, remoteURL)
+		}
+	}
+
+	keepRepoDir = true
+	if err := openDirectory(repoPath); err != nil {
+		return nil, fmt.Errorf("open repo directory: %w", err)
+	}
+
+	return &GenerateRepoResponse{
+		RepoPath:    repoPath,
+		CommitCount: totalCommits,
+		RemoteURL:   remoteURL,
+	}, nil
+}
+
+// commitIdentity picks the name and email of the generated commits: the design's identity,
+// then the signed-in account where there is one, else what the request gives. Unless the
+// request opts out, the user confirms an email GitHub won't attribute to them; without a
+// window to ask in, unattendedCommitEmail decides instead.
+func (a *App) commitIdentity(req GenerateRepoRequest) (string, string, error) {
+	session := a.session()
+	username := strings.TrimSpace(req.GithubUsername)
+	if session.User != nil && strings.TrimSpace(session.User.Login) != "" {
+		username = strings.TrimSpace(session.User.Login)
+	}
+	if username == "" {
+		username = "greenwall"
+	}
+	email := strings.TrimSpace(req.GithubEmail)
+	if req.Identity != nil && strings.TrimSpace(req.Identity.Email) != "" {
+		email = strings.TrimSpace(req.Identity.Email)
+	}
+	explicit := email != ""
+	if email == "" && session.User != nil && strings.TrimSpace(session.User.Email) != "" {
+		email = strings.TrimSpace(session.User.Email)
+	}
+	if email == "" && session.User != nil {
+		email = session.noReplyEmail()
+	}
+	if email == "" {
+		email = fmt.Sprintf("%s@users.noreply.github.com", username)
+	}
+	// The design's name is a display name, so it only replaces the login once the email is settled.
+	if req.Identity != nil && strings.TrimSpace(req.Identity.Name) != "" {
+		username = strings.TrimSpace(req.Identity.Name)
+	}
+	if !req.AllowUnattributedEmail && session.checkUserSession("check the commit email") == nil {
+		warning, err := a.commitEmailWarning(session, email)
+		if err != nil {
+			return "", "", err
+		}
+		if warning != "" && a.ctx == nil {
+			if email, err = unattendedCommitEmail(warning, email, explicit, session.noReplyEmail()); err != nil {
+				return "", "", err
+			}
+		} else if warning != "" {
+			proceed, err := a.confirmCommitEmail(warning)
+			if err != nil {
+				return "", "", err
+			}
+			if !proceed {
+				return "", "", fmt.Errorf("generation cancelled")
+			}
+		}
+	}
+
+	return username, email, nil
+}
+
+// newContributionHistory describes the history generated for req: the repository name, the
+// branch and the LICENSE file follow the remote repository when there is one. GenerateRepo
+// and ExportFastImport share it, so an exported stream recreates the commits generation pushes.
+func (a *App) newContributionHistory(req GenerateRepoRequest, remote *RemoteRepoOptions, session githubSession, auth githubAuth, username, email string, location *time.Location) (contributionHistory, error) {
+	history := contributionHistory{
+		RepoName:      strings.TrimSpace(req.RepoName),
+		Username:      username,
+		Email:         email,
+		Branch:        defaultBranchName,
+		Year:          req.Year,
+		Location:      location,
+		Contributions: req.Contributions,
+	}
+	if remote != nil {
+		history.RepoName = remote.Name
+		history.Branch = remote.DefaultBranch
+		// The license is committed as part of the generated history rather than sent as
+		// license_template: GitHub would otherwise auto-initialise the repository with a
+		// commit of its own and reject the push.
+		if remote.LicenseTemplate != "" {
+			template, err := a.fetchLicenseTemplate(auth, remote.LicenseTemplate)
+			if err != nil {
+				return contributionHistory{}, err
+			}
+			licenseYear := req.Year
+			if licenseYear <= 0 {
+				licenseYear = time.Now().Year()
+			}
+			holder := username
+			if session.User != nil && strings.TrimSpace(session.User.Name) != "" {
+				holder = strings.TrimSpace(session.User.Name)
+			}
+			history.License = renderLicense(template, licenseYear, holder)
+		}
+		return history, nil
+	}
+
+	if history.RepoName == "" {
+		history.RepoName = username
+		if req.Year > 0 {
+			history.RepoName = fmt.Sprintf("%s-%d", history.RepoName, req.Year)
+		}
+	}
+	history.RepoName = sanitiseRepoName(history.RepoName)
+	if history.RepoName == "" {
+		history.RepoName = "contributions"
+	}
+	return history, nil
+}
+
+// commitLocation loads the time zone commits are dated in; empty is UTC.
+func commitLocation(name string) (*time.Location, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return time.UTC, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return location, nil
+}
+
+// contributionHistory describes a generated history. The same description always yields
+// the same fast-import stream, and so the same commit hashes.
+type contributionHistory struct {
+	RepoName      string
+	Username      string
+	Email         string
+	Branch        string
+	Year          int
+	Location      *time.Location
+	License       string // empty leaves the LICENSE file out
+	Contributions []ContributionDay
+}
+
+func (h *contributionHistory) readme() string {
+	return fmt.Sprintf("# %s\n\nGenerated with https://github.com/zmrlft/GreenWall.\n", h.RepoName)
+}
+
+// buildFastImportStream renders the history as a git fast-import stream and returns it
+// with the number of commits it creates.
+func buildFastImportStream(h contributionHistory) (*bytes.Buffer, int, error) {
+	location := h.Location
+	if location == nil {
+		location = time.UTC
+	}
+
 	// Sort contributions by date ascending to produce chronological history
-	contribs := make([]ContributionDay, 0, len(req.Contributions))
-	for _, c := range req.Contributions {
+	contribs := make([]ContributionDay, 0, len(h.Contributions))
+	for _, c := range h.Contributions {
 		if c.Count > 0 {
 			contribs = append(contribs, c)
 		}
 	}
 	sort.Slice(contribs, func(i, j int) bool { return contribs[i].Date < contribs[j].Date })
 
+	manifestContent, err := buildGreenWallManifest(h.Year, contribs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// Files that stay the same in every commit; blob i is marked :i+1.
+	staticFiles := []struct{ path, content string }{
+		{"README.md", h.readme()},
+		{greenWallManifestFile, manifestContent},
+	}
+	if h.License != "" {
+		staticFiles = append(staticFiles, struct{ path, content string }{"LICENSE", h.License})
+	}
+
 	// Build fast-import stream
 	var stream bytes.Buffer
-	// Create README blob once and mark it
-	fmt.Fprintf(&stream, "blob\nmark :1\n")
-	fmt.Fprintf(&stream, "data %d\n%s\n", len(readmeContent), readmeContent)
+	// Create the static blobs once and mark them
+	for i, file := range staticFiles {
+		fmt.Fprintf(&stream, "blob\nmark :%d\n", i+1)
+		fmt.Fprintf(&stream, "data %d\n%s\n", len(file.content), file.content)
+	}
 
 	// Prepare to accumulate activity log content across commits
 	var activityBuf bytes.Buffer
-	nextMark := 2
+	nextMark := len(staticFiles) + 1
 	totalCommits := 0
-	branch := "refs/heads/main"
+	branch := "refs/heads/" + h.Branch
 
 	for _, day := range contribs {
 		parsedDate, err := time.Parse("2006-01-02", day.Date)
 		if err != nil {
-			return nil, fmt.Errorf("invalid date %q: %w", day.Date, err)
+			return nil, 0, fmt.Errorf("invalid date %q: %w", day.Date, err)
 		}
 		for i := 0; i < day.Count; i++ {
 			// Update activity content in-memory
@@ -342,16 +662,18 @@ func (a *App) GenerateRepo(req GenerateRepoRequest) (*GenerateRepoResponse, erro
 			stream.WriteString("\n")
 
 			// Emit commit that points to README (:1) and activity (:nextMark)
-			// Shift to midday UTC so GitHub won't classify the commit into the previous day across time zones.
-			commitTime := parsedDate.Add(12*time.Hour + time.Duration(i)*time.Second)
+			// Shift to midday so GitHub won't classify the commit into the previous day across time zones.
+			commitTime := time.Date(parsedDate.Year(), parsedDate.Month(), parsedDate.Day(), 12, 0, i, 0, location)
 			secs := commitTime.Unix()
 			tz := commitTime.Format("-0700")
 			msg := fmt.Sprintf("Contribution on %s (%d/%d)", day.Date, i+1, day.Count)
 			fmt.Fprintf(&stream, "commit %s\n", branch)
-			fmt.Fprintf(&stream, "author %s <%s> %d %s\n", username, email, secs, tz)
-			fmt.Fprintf(&stream, "committer %s <%s> %d %s\n", username, email, secs, tz)
+			fmt.Fprintf(&stream, "author %s <%s> %d %s\n", h.Username, h.Email, secs, tz)
+			fmt.Fprintf(&stream, "committer %s <%s> %d %s\n", h.Username, h.Email, secs, tz)
 			fmt.Fprintf(&stream, "data %d\n%s\n", len(msg), msg)
-			fmt.Fprintf(&stream, "M 100644 :1 %s\n", filepath.Base(readmePath))
+			for i, file := range staticFiles {
+				fmt.Fprintf(&stream, "M 100644 :%d %s\n", i+1, file.path)
+			}
 			fmt.Fprintf(&stream, "M 100644 :%d activity.log\n", nextMark)
 
 			nextMark++
@@ -359,52 +681,7 @@ func (a *App) GenerateRepo(req GenerateRepoRequest) (*GenerateRepoResponse, erro
 		}
 	}
 	stream.WriteString("done\n")
-
-	// Feed stream to fast-import
-	if totalCommits > 0 {
-		if err := a.runGitFastImport(repoPath, &stream); err != nil {
-			return nil, fmt.Errorf("fast-import failed: %w", err)
-		}
-		// Update working tree to the generated branch for user convenience
-		_ = a.runGitCommand(repoPath, "checkout", "-f", "main")
-	}
-
-	var remoteURL string
-	if remoteOptions != nil {
-		createdRepo, err := a.createGithubRepository(remoteOptions)
-		if err != nil {
-			return nil, err
-		}
-		targetURL := strings.TrimSpace(createdRepo.CloneURL)
-		if targetURL == "" {
-			return nil, fmt.Errorf("GitHub did not return a clone URL for the new repository")
-		}
-		ownerLogin := createdRepo.Owner.Login
-		if ownerLogin == "" && a.githubUser != nil {
-			ownerLogin = a.githubUser.Login
-		}
-		if err := a.configureRemoteAndPush(repoPath, targetURL, ownerLogin, a.githubToken); err != nil {
-			return nil, err
-		}
-		if createdRepo.HTMLURL != "" {
-			remoteURL = createdRepo.HTMLURL
-		} else {
-			remoteURL = targetURL
-		}
-		if remoteURL != "" && a.ctx != nil {
-			runtime.BrowserOpenURL(a.ctx, remoteURL)
-		}
-	}
-
-	if err := openDirectory(repoPath); err != nil {
-		return nil, fmt.Errorf("open repo directory: %w", err)
-	}
-
-	return &GenerateRepoResponse{
-		RepoPath:    repoPath,
-		CommitCount: totalCommits,
-		RemoteURL:   remoteURL,
-	}, nil
+	return &stream, totalCommits, nil
 }
 
 type ExportContributionsRequest struct {
@@ -444,24 +721,39 @@ func (a *App) ExportContributions(req ExportContributionsRequest) (*ExportContri
 	return &ExportContributionsResponse{FilePath: filePath}, nil
 }
 
+type ImportContributionsRequest struct {
+	FilePath        string `json:"filePath,omitempty"`        // empty asks with a file dialog
+	Year            int    `json:"year,omitempty"`            // leave out days of a contributions array from other years; 0 keeps them all
+	DuplicatePolicy string `json:"duplicatePolicy,omitempty"` // last (default), first, sum, max or error
+}
+
 type ImportContributionsResponse struct {
 	Contributions []ContributionDay `json:"contributions"`
+	Design        *DesignDocument   `json:"design,omitempty"` // set for .greenwall files
+	Report        *ImportReport     `json:"report"`
 }
 
-// ImportContributions imports contributions from a JSON file.
-func (a *App) ImportContributions() (*ImportContributionsResponse, error) {
-	// 使用对话框让用户选择导入文件
-	filePath, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
-		Title: "导入贡献数据",
-		Filters: []runtime.FileFilter{
-			{DisplayName: "JSON 文件 (*.json)", Pattern: "*.json"},
-		},
-	})
-	if err != nil {
-		return nil, fmt.Errorf("open file dialog: %w", err)
-	}
+// ImportContributions imports contributions from a .greenwall design, a JSON file or a text
+// grid. Entries that can't be imported fail the import with their position in the file;
+// duplicates are merged by the request's policy and the report lists what was changed or
+// left out.
+func (a *App) ImportContributions(req ImportContributionsRequest) (*ImportContributionsResponse, error) {
+	filePath := strings.TrimSpace(req.FilePath)
 	if filePath == "" {
-		return nil, fmt.Errorf("import cancelled")
+		// 使用对话框让用户选择导入文件
+		var err error
+		filePath, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "导入贡献数据",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "GreenWall 设计 (*.greenwall;*.json;*.txt)", Pattern: "*" + designFileExtension + ";*.json;*" + gridFileExtension},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open file dialog: %w", err)
+		}
+		if filePath == "" {
+			return nil, fmt.Errorf("import cancelled")
+		}
 	}
 
 	data, err := os.ReadFile(filePath)
@@ -469,12 +761,15 @@ func (a *App) ImportContributions() (*ImportContributionsResponse, error) {
 		return nil, fmt.Errorf("read contributions file: %w", err)
 	}
 
-	var contributions []ContributionDay
-	if err := json.Unmarshal(data, &contributions); err != nil {
-		return nil, fmt.Errorf("unmarshal contributions: %w", err)
+	design, contributions, report, err := validateContributionsFile(data, importValidationOptions{
+		Year:       req.Year,
+		Duplicates: req.DuplicatePolicy,
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return &ImportContributionsResponse{Contributions: contributions}, nil
+	return &ImportContributionsResponse{Contributions: contributions, Design: design, Report: report}, nil
 }
 
 func sanitiseRepoName(input string) string {
@@ -487,17 +782,24 @@ func sanitiseRepoName(input string) string {
 	if input == "" {
 		return ""
 	}
-	if len(input) > 64 {
-		input = input[:64]
+	if len(input) > maxRepoNameLength {
+		input = input[:maxRepoNameLength]
 	}
 	return input
 }
 
-func (a *App) runGitCommand(dir string, args ...string) error {
-	gitCmd := a.getGitCommand()
-	cmd := exec.Command(gitCmd, args...)
+// gitCommand prepares git to run in dir. The network settings are always in its
+// environment, so every call that may reach a remote honours them.
+func (a *App) gitCommand(dir string, args ...string) *exec.Cmd {
+	cmd := exec.Command(a.getGitCommand(), args...)
 	cmd.Dir = dir
 	configureCommand(cmd, true)
+	cmd.Env = append(os.Environ(), a.gitNetworkEnv()...)
+	return cmd
+}
+
+func (a *App) runGitCommand(dir string, args ...string) error {
+	cmd := a.gitCommand(dir, args...)
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -511,10 +813,7 @@ func (a *App) runGitCommand(dir string, args ...string) error {
 
 // runGitFastImport runs `git fast-import` with the given stream as stdin.
 func (a *App) runGitFastImport(dir string, r *bytes.Buffer) error {
-	gitCmd := a.getGitCommand()
-	cmd := exec.Command(gitCmd, "fast-import", "--quiet")
-	cmd.Dir = dir
-	configureCommand(cmd, true)
+	cmd := a.gitCommand(dir, "fast-import", "--quiet")
 	cmd.Stdin = r
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -529,87 +828,86 @@ func (a *App) AuthenticateWithToken(req GithubAuthRequest) (*GithubAuthResponse,
 		return nil, fmt.Errorf("token cannot be empty")
 	}
 
-	user, err := a.fetchGithubUser(token)
+	host, err := normaliseGithubHost(req.Host)
 	if err != nil {
 		return nil, err
 	}
 
-	a.githubToken = token
-	a.githubUser = user
-	a.emitGithubAuthChanged()
-
-	if req.Remember {
-		if err := a.saveGithubToken(token); err != nil && a.ctx != nil {
-			runtime.LogWarningf(a.ctx, "failed to store GitHub token: %v", err)
-		}
-	} else {
-		if err := a.clearSavedToken(); err != nil && a.ctx != nil {
-			runtime.LogWarningf(a.ctx, "failed to clear saved GitHub token: %v", err)
-		}
-	}
-
-	return &GithubAuthResponse{
-		User:       cloneGithubUser(user),
-		Remembered: req.Remember,
-	}, nil
+	return a.signInWithToken(host, token, req.Remember, req.Passphrase)
 }
 
 func (a *App) GetGithubLoginStatus() *GithubLoginStatus {
-	if a.githubUser == nil {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	acc := a.activeAccount()
+	if acc == nil || acc.User == nil {
 		return &GithubLoginStatus{Authenticated: false}
 	}
 
-	return &GithubLoginStatus{
-		Authenticated: true,
-		User:          cloneGithubUser(a.githubUser),
-	}
+	status := &GithubLoginStatus{
+		Authenticated:    true,
+		AccountID:        acc.ID,
+		User:             acc.profile(),
+		Permissions:      cloneTokenPermissions(acc.permissions),
+		Expired:          acc.Expired,
+		Unverified:       acc.unverified != "",
+		UnverifiedReason: acc.unverified,
+	}
+	if acc.ExpiresAt != nil {
+		expiresAt := *acc.ExpiresAt
+		status.ExpiresAt = &expiresAt
+	}
+	return status
 }
 
+// LogoutGithub signs the active account out and forgets it.
 func (a *App) LogoutGithub() error {
-	a.githubToken = ""
-	a.githubUser = nil
-	a.emitGithubAuthChanged()
-	return a.clearSavedToken()
+	id := a.session().AccountID
+	if id == "" {
+		return nil
+	}
+	return a.RemoveGithubAccount(id)
 }
 
-func (a *App) fetchGithubUser(token string) (*GithubUserProfile, error) {
-	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user", nil)
+// fetchGithubUser returns the token's user along with the response headers, which tell
+// inspectTokenPermissions what the token may do.
+func (a *App) fetchGithubUser(host, token string) (*GithubUserProfile, http.Header, error) {
+	req, err := a.newGithubRequestForHost(host, http.MethodGet, "/user", token, nil)
 	if err != nil {
-		return nil, fmt.Errorf("build GitHub request failed: %w", err)
+		return nil, nil, fmt.Errorf("build GitHub request failed: %w", err)
 	}
 
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := a.doGithubRequest(req)
 	if err != nil {
-		return nil, fmt.Errorf("fetch GitHub user failed: %w", err)
+		return nil, nil, fmt.Errorf("fetch GitHub user failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("token invalid or expired")
+		return nil, nil, errTokenRejected
+	}
+	if resp.StatusCode >= 500 {
+		return nil, nil, fmt.Errorf("%w: GitHub API returned %d for /user", errGithubUnavailable, resp.StatusCode)
 	}
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
-		return nil, fmt.Errorf("GitHub API returned error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+		return nil, nil, fmt.Errorf("GitHub API returned error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
 	}
 
 	var payload struct {
+		ID        int64  `json:"id"`
 		Login     string `json:"login"`
 		Name      string `json:"name"`
 		Email     string `json:"email"`
 		AvatarURL string `json:"avatar_url"`
 	}
 	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
-		return nil, fmt.Errorf("decode GitHub user payload failed: %w", err)
+		return nil, nil, fmt.Errorf("decode GitHub user payload failed: %w", err)
 	}
 
 	email := payload.Email
 	if email == "" {
-		if emails, err := a.fetchGithubEmails(token); err != nil {
+		if emails, err := a.fetchGithubEmails(host, token); err != nil {
 			if a.ctx != nil {
 				runtime.LogWarningf(a.ctx, "fetch GitHub emails failed: %v", err)
 			}
@@ -624,32 +922,28 @@ func (a *App) fetchGithubUser(token string) (*GithubUserProfile, error) {
 	}
 
 	return &GithubUserProfile{
+		ID:        payload.ID,
 		Login:     payload.Login,
 		Name:      payload.Name,
 		Email:     email,
 		AvatarURL: payload.AvatarURL,
-	}, nil
+	}, resp.Header, nil
 }
 
-func (a *App) fetchGithubEmails(token string) ([]githubEmailEntry, error) {
-	req, err := http.NewRequest(http.MethodGet, "https://api.github.com/user/emails", nil)
+func (a *App) fetchGithubEmails(host, token string) ([]githubEmailEntry, error) {
+	req, err := a.newGithubRequestForHost(host, http.MethodGet, "/user/emails", token, nil)
 	if err != nil {
 		return nil, fmt.Errorf("build GitHub email request failed: %w", err)
 	}
 
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Authorization", "Bearer "+token)
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := a.doGithubRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("fetch GitHub emails failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode == http.StatusUnauthorized {
-		return nil, fmt.Errorf("token invalid or expired when fetching emails")
+		return nil, fmt.Errorf("%w when fetching emails", errTokenRejected)
 	}
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
@@ -664,6 +958,49 @@ func (a *App) fetchGithubEmails(token string) ([]githubEmailEntry, error) {
 	return entries, nil
 }
 
+// newGithubRequest builds a REST API request for the active account's host, authenticated
+// with token as given; callers take it from activeGithubToken. A non-nil payload is sent as JSON.
+func (a *App) newGithubRequest(method, path, token string, payload interface{}) (*http.Request, error) {
+	return a.newGithubRequestForHost(a.session().Host, method, path, token, payload)
+}
+
+func (a *App) newGithubRequestForHost(host, method, path, token string, payload interface{}) (*http.Request, error) {
+	var body io.Reader
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, fmt.Errorf("encode GitHub request payload: %w", err)
+		}
+		body = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, a.apiBaseForHost(host)+path, body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+func (a *App) doGithubRequest(req *http.Request) (*http.Response, error) {
+	resp, err := a.httpClient(10 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	a.observeTokenResponse(req, resp)
+	if err := a.checkSSOResponse(req, resp); err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	return resp, nil
+}
+
 func pickBestEmail(entries []githubEmailEntry) string {
 	for _, entry := range entries {
 		if entry.Primary && entry.Verified && entry.Email != "" {
@@ -683,8 +1020,9 @@ func pickBestEmail(entries []githubEmailEntry) string {
 	return ""
 }
 
-func (a *App) createGithubRepository(opts *RemoteRepoOptions) (*githubRepository, error) {
-	if a.githubToken == "" {
+// createGithubRepository creates the repository for session's account with auth.
+func (a *App) createGithubRepository(auth githubAuth, session githubSession, opts *RemoteRepoOptions) (*githubRepository, error) {
+	if auth.Token == "" {
 		return nil, fmt.Errorf("missing GitHub token for remote repository creation")
 	}
 
@@ -695,23 +1033,34 @@ func (a *App) createGithubRepository(opts *RemoteRepoOptions) (*githubRepository
 	if desc := strings.TrimSpace(opts.Description); desc != "" {
 		payload["description"] = desc
 	}
+	if opts.Homepage != "" {
+		payload["homepage"] = opts.Homepage
+	}
+	if opts.DisableIssues {
+		payload["has_issues"] = false
+	}
+	if opts.DisableWiki {
+		payload["has_wiki"] = false
+	}
+	if opts.DisableProjects {
+		payload["has_projects"] = false
+	}
 
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return nil, fmt.Errorf("encode GitHub repository payload: %w", err)
+	// Installation tokens can only create repositories in the organisation the app is installed on.
+	path := "/user/repos"
+	if session.App != nil {
+		if session.App.OwnerType != "Organization" {
+			return nil, fmt.Errorf("GitHub App installations can only create repositories in an organisation; %s is a user account", session.User.Login)
+		}
+		path = "/orgs/" + url.PathEscape(session.User.Login) + "/repos"
 	}
 
-	req, err := http.NewRequest(http.MethodPost, "https://api.github.com/user/repos", bytes.NewReader(body))
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodPost, path, auth.Token, payload)
 	if err != nil {
 		return nil, fmt.Errorf("build GitHub repository request failed: %w", err)
 	}
-	req.Header.Set("Accept", "application/vnd.github+json")
-	req.Header.Set("Authorization", "Bearer "+a.githubToken)
-	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
-	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	resp, err := a.doGithubRequest(req)
 	if err != nil {
 		return nil, fmt.Errorf("create GitHub repository failed: %w", err)
 	}
@@ -729,9 +1078,9 @@ func (a *App) createGithubRepository(opts *RemoteRepoOptions) (*githubRepository
 	return &repo, nil
 }
 
-func (a *App) configureRemoteAndPush(repoPath string, remoteURL string, username string, token string) error {
-	if username == "" && a.githubUser != nil {
-		username = a.githubUser.Login
+func (a *App) configureRemoteAndPush(repoPath string, remoteURL string, branch string, username string, token string) error {
+	if user := a.session().User; username == "" && user != nil {
+		username = user.Login
 	}
 	if username == "" {
 		username = "git"
@@ -744,32 +1093,26 @@ func (a *App) configureRemoteAndPush(repoPath string, remoteURL string, username
 		return fmt.Errorf("add remote origin: %w", err)
 	}
 
-	if err := a.gitPushWithToken(repoPath, username, token); err != nil {
+	if err := a.gitPushWithToken(repoPath, branch, username, token); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (a *App) gitPushWithToken(repoPath, username, token string) error {
+func (a *App) gitPushWithToken(repoPath, branch, username, token string) error {
 	helperPath, cleanup, err := createGitAskPassHelper()
 	if err != nil {
 		return err
 	}
 	defer cleanup()
 
-	gitCmd := a.getGitCommand()
-	cmd := exec.Command(gitCmd, "push", "-u", "origin", "main")
-	cmd.Dir = repoPath
-	configureCommand(cmd, true)
-
-	env := os.Environ()
-	env = append(env,
+	cmd := a.gitCommand(repoPath, "push", "-u", "origin", branch)
+	cmd.Env = append(cmd.Env,
 		fmt.Sprintf("GIT_ASKPASS=%s", helperPath),
 		"GIT_TERMINAL_PROMPT=0",
 		fmt.Sprintf("GITHUB_ASKPASS_USERNAME=%s", username),
 		fmt.Sprintf("GITHUB_ASKPASS_TOKEN=%s", token),
 	)
-	cmd.Env = env
 
 	var stderr bytes.Buffer
 	cmd.Stderr = &stderr
@@ -819,57 +1162,16 @@ func createGitAskPassHelper() (string, func(), error) {
 	return path, cleanup, nil
 }
 
-func (a *App) saveGithubToken(token string) error {
-	path, err := a.tokenStoragePath()
-	if err != nil {
-		return err
-	}
-	return os.WriteFile(path, []byte(token), 0o600)
-}
-
-func (a *App) clearSavedToken() error {
-	path, err := a.tokenStoragePath()
-	if err != nil {
-		return err
-	}
-	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
-		return err
-	}
-	return nil
-}
-
-func (a *App) loadRememberedGithubToken() error {
-	path, err := a.tokenStoragePath()
-	if err != nil {
-		return err
-	}
-
-	data, err := os.ReadFile(path)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil
-		}
-		return err
-	}
-
-	token := strings.TrimSpace(string(data))
-	if token == "" {
-		return nil
-	}
-
-	user, err := a.fetchGithubUser(token)
+func (a *App) tokenStoragePath() (string, error) {
+	appDir, err := appConfigDir()
 	if err != nil {
-		_ = os.Remove(path)
-		return err
+		return "", err
 	}
-
-	a.githubToken = token
-	a.githubUser = user
-	a.emitGithubAuthChanged()
-	return nil
+	return filepath.Join(appDir, "github_token"), nil
 }
 
-func (a *App) tokenStoragePath() (string, error) {
+// appConfigDir returns the per-user green-wall config directory, creating it if needed.
+func appConfigDir() (string, error) {
 	dir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
@@ -879,8 +1181,7 @@ func (a *App) tokenStoragePath() (string, error) {
 	if err := os.MkdirAll(appDir, 0o700); err != nil {
 		return "", err
 	}
-
-	return filepath.Join(appDir, "github_token"), nil
+	return appDir, nil
 }
 
 func cloneGithubUser(user *GithubUserProfile) *GithubUserProfile {
@@ -896,10 +1197,5 @@ func (a *App) emitGithubAuthChanged() {
 		return
 	}
 
-	status := &GithubLoginStatus{
-		Authenticated: a.githubUser != nil,
-		User:          cloneGithubUser(a.githubUser),
-	}
-
-	runtime.EventsEmit(a.ctx, githubAuthChangedEvent, status)
+	runtime.EventsEmit(a.ctx, githubAuthChangedEvent, a.GetGithubLoginStatus())
 }
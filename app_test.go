@@ -0,0 +1,86 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestApp returns an App whose GitHub API and web endpoints are served by handler.
+// The configuration directory is redirected to a temporary one.
+func newTestApp(t *testing.T, handler http.Handler) (*App, *httptest.Server) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", dir)
+	t.Setenv("HOME", dir)
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	app := NewApp()
+	app.githubAPIBase = server.URL
+	app.githubWebBase = server.URL
+	return app, server
+}
+
+func TestCommitIdentityUsesDesignIdentity(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	tests := []struct {
+		name      string
+		identity  *DesignIdentity
+		wantName  string
+		wantEmail string
+	}{
+		{name: "no identity", wantName: "octocat", wantEmail: "octocat@users.noreply.github.com"},
+		{
+			name:      "full identity",
+			identity:  &DesignIdentity{Name: "Octo Cat", Email: "octo@example.com"},
+			wantName:  "Octo Cat",
+			wantEmail: "octo@example.com",
+		},
+		{
+			name:      "name only keeps the login's email",
+			identity:  &DesignIdentity{Name: " Octo Cat "},
+			wantName:  "Octo Cat",
+			wantEmail: "octocat@users.noreply.github.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := GenerateRepoRequest{GithubUsername: "octocat", Identity: tt.identity, AllowUnattributedEmail: true}
+			name, email, err := app.commitIdentity(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if name != tt.wantName || email != tt.wantEmail {
+				t.Errorf("commitIdentity() = %q, %q, want %q, %q", name, email, tt.wantName, tt.wantEmail)
+			}
+		})
+	}
+}
+
+func TestCreateGithubRepositoryUsesGivenAuth(t *testing.T) {
+	var gotAuth string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(`{"name":"wall","clone_url":"https://github.com/octocat/wall.git","owner":{"login":"octocat"}}`))
+	}))
+	// The active account changed after generation started.
+	other := &githubAccount{ID: "github.com/hubot", Host: defaultGithubHost, User: &GithubUserProfile{Login: "hubot"}, token: "gho_hubot"}
+	app.accounts = []*githubAccount{other}
+	app.activeAccountID = other.ID
+
+	session := githubSession{AccountID: "github.com/octocat", Host: defaultGithubHost, User: &GithubUserProfile{Login: "octocat"}}
+	auth := githubAuth{AccountID: session.AccountID, Host: defaultGithubHost, Token: "gho_octocat"}
+	repo, err := app.createGithubRepository(auth, session, &RemoteRepoOptions{Name: "wall"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotAuth != "Bearer gho_octocat" {
+		t.Errorf("Authorization = %q, want the token generation started with", gotAuth)
+	}
+	if repo.Owner.Login != "octocat" {
+		t.Errorf("owner = %q, want %q", repo.Owner.Login, "octocat")
+	}
+}
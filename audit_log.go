@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Audited actions.
+const (
+	auditActionLogin          = "login"
+	auditActionAccountSwitch  = "account-switch"
+	auditActionAccountRemove  = "account-remove"
+	auditActionTokenExpired   = "token-expired"
+	auditActionGenerate       = "generate"
+	auditActionRepoCreate     = "repo-create"
+	auditActionRepoPush       = "repo-push"
+	auditActionRepoDelete     = "repo-delete"
+	auditActionRepoArchive    = "repo-archive"
+	auditActionRepoUnarchive  = "repo-unarchive"
+	auditActionRepoVisibility = "repo-visibility"
+)
+
+const (
+	auditOutcomeSuccess = "success"
+	auditOutcomeFailure = "failure"
+)
+
+// auditMu serialises appends so concurrent writers never interleave lines.
+var auditMu sync.Mutex
+
+// tokenPattern matches the GitHub token formats, bearer credentials and credentials
+// embedded in URLs.
+var tokenPattern = regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9_]{16,}|github_pat_[A-Za-z0-9_]{22,})\b|(?i)(bearer\s+)[A-Za-z0-9._~+/=-]+|(://[^/\s:@]+:)[^@\s/]+@`)
+
+// AuditEntry is one line of the audit log.
+type AuditEntry struct {
+	Time      time.Time         `json:"time"`
+	AccountID string            `json:"accountId,omitempty"`
+	Action    string            `json:"action"`
+	Repo      string            `json:"repo,omitempty"` // owner/name, or the local path for local generation
+	Outcome   string            `json:"outcome"`
+	Error     string            `json:"error,omitempty"`
+	Details   map[string]string `json:"details,omitempty"`
+}
+
+type AuditLogQuery struct {
+	AccountID string `json:"accountId,omitempty"`
+	Action    string `json:"action,omitempty"`
+	Repo      string `json:"repo,omitempty"`    // case-insensitive substring
+	Outcome   string `json:"outcome,omitempty"` // "success" or "failure"
+	Since     string `json:"since,omitempty"`   // YYYY-MM-DD or RFC 3339
+	Until     string `json:"until,omitempty"`   // YYYY-MM-DD (inclusive) or RFC 3339
+	Limit     int    `json:"limit,omitempty"`   // newest entries first; 0 returns all
+}
+
+type ExportAuditLogResponse struct {
+	FilePath string `json:"filePath"`
+	Count    int    `json:"count"`
+}
+
+// QueryAuditLog returns matching audit entries, newest first.
+func (a *App) QueryAuditLog(query AuditLogQuery) ([]AuditEntry, error) {
+	entries, err := readAuditLog(query)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Time.After(entries[j].Time) })
+	if query.Limit > 0 && len(entries) > query.Limit {
+		entries = entries[:query.Limit]
+	}
+	return entries, nil
+}
+
+// ExportAuditLog writes the matching entries, oldest first, to a JSONL file chosen by the user.
+func (a *App) ExportAuditLog(query AuditLogQuery) (*ExportAuditLogResponse, error) {
+	query.Limit = 0
+	entries, err := readAuditLog(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range entries {
+		if err := encoder.Encode(entry); err != nil {
+			return nil, fmt.Errorf("encode audit entry: %w", err)
+		}
+	}
+
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出审计日志",
+		DefaultFilename: "green-wall-audit.jsonl",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "JSONL 文件 (*.jsonl)", Pattern: "*.jsonl"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+
+	if err := os.WriteFile(filePath, buf.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write audit log to file: %w", err)
+	}
+	return &ExportAuditLogResponse{FilePath: filePath, Count: len(entries)}, nil
+}
+
+// audit records an action by the active account. Failing to write the log never fails the action.
+func (a *App) audit(action, repo string, actionErr error, details map[string]string) {
+	a.auditAs(a.session().AccountID, action, repo, actionErr, details)
+}
+
+func (a *App) auditAs(accountID, action, repo string, actionErr error, details map[string]string) {
+	entry := AuditEntry{
+		Time:      time.Now().UTC(),
+		AccountID: accountID,
+		Action:    action,
+		Repo:      repo,
+		Outcome:   auditOutcomeSuccess,
+	}
+	if actionErr != nil {
+		entry.Outcome = auditOutcomeFailure
+		entry.Error = a.redactSecrets(actionErr.Error())
+	}
+	if len(details) > 0 {
+		entry.Details = make(map[string]string, len(details))
+		for key, value := range details {
+			entry.Details[key] = a.redactSecrets(value)
+		}
+	}
+
+	if err := appendAuditEntry(entry); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to write audit log: %v", err)
+	}
+}
+
+// redactSecrets removes tokens from text bound for the audit log: the tokens of known
+// accounts verbatim, and anything else shaped like a credential.
+func (a *App) redactSecrets(text string) string {
+	a.mu.Lock()
+	for _, acc := range a.accounts {
+		if acc.token != "" {
+			text = strings.ReplaceAll(text, acc.token, "[REDACTED]")
+		}
+	}
+	a.mu.Unlock()
+	return tokenPattern.ReplaceAllStringFunc(text, func(match string) string {
+		groups := tokenPattern.FindStringSubmatch(match)
+		switch {
+		case groups[2] != "":
+			return groups[2] + "[REDACTED]"
+		case groups[3] != "":
+			return groups[3] + "[REDACTED]@"
+		default:
+			return "[REDACTED]"
+		}
+	})
+}
+
+func auditLogPath() (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "audit.jsonl"), nil
+}
+
+func appendAuditEntry(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	path, err := auditLogPath()
+	if err != nil {
+		return err
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func readAuditLog(query AuditLogQuery) ([]AuditEntry, error) {
+	since, err := parseTimeBound(query.Since, false)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseTimeBound(query.Until, true)
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := auditLogPath()
+	if err != nil {
+		return nil, err
+	}
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []AuditEntry{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var entry AuditEntry
+		// A line cut short by a crash shouldn't hide the rest of the log.
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		if query.AccountID != "" && entry.AccountID != query.AccountID ||
+			query.Action != "" && entry.Action != query.Action ||
+			query.Outcome != "" && entry.Outcome != query.Outcome ||
+			query.Repo != "" && !strings.Contains(strings.ToLower(entry.Repo), strings.ToLower(query.Repo)) ||
+			!since.IsZero() && entry.Time.Before(since) ||
+			!until.IsZero() && !entry.Time.Before(until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read audit log: %w", err)
+	}
+	return entries, nil
+}
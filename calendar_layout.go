@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"time"
+)
+
+var calendarMonthNames = [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"}
+
+// penLevelCounts are the commit counts of the editor's pens for each shade.
+var penLevelCounts = [5]int{0, 1, 3, 6, 9}
+
+// contributionLevel maps a day's commit count to its shade, using the same thresholds as
+// the editor.
+func contributionLevel(count int) int {
+	switch {
+	case count <= 0:
+		return 0
+	case count <= 2:
+		return 1
+	case count <= 5:
+		return 2
+	case count <= 8:
+		return 3
+	default:
+		return 4
+	}
+}
+
+// calendarLayout places days on the week grid the editor and GitHub draw: one column per
+// week, one row per weekday with Sunday on top.
+type calendarLayout struct {
+	start, end time.Time // first and last day shown, inclusive
+	startRow   int       // weekday of start
+	weeks      int
+	counts     map[string]int
+}
+
+type calendarMonthLabel struct {
+	Column int
+	Name   string
+}
+
+// maxCalendarWeeks caps a calendar drawn from days that span several years; the latest
+// weeks are kept, as on a profile.
+const maxCalendarWeeks = 53
+
+// newCalendarLayout lays out year, or when year is 0 the year the days fall in, or the
+// last maxCalendarWeeks weeks of the days if they cover several years.
+func newCalendarLayout(days []ContributionDay, year int) (*calendarLayout, error) {
+	counts, first, last, err := countCalendarDays(days)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case year > 0:
+	case first.IsZero():
+		return nil, fmt.Errorf("no contributions to draw; pass a year to draw an empty calendar")
+	case first.Year() == last.Year():
+		year = first.Year()
+	default:
+		if earliest := last.AddDate(0, 0, -int(last.Weekday())-7*(maxCalendarWeeks-1)); first.Before(earliest) {
+			first = earliest
+		}
+		return spanCalendarLayout(counts, first, last), nil
+	}
+	return spanCalendarLayout(counts, time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)), nil
+}
+
+// newDesignCalendarLayout lays out a decoded file the way it was designed: year when it is
+// given, else the design's window or year, else as newCalendarLayout does.
+func newDesignCalendarLayout(design *DesignDocument, days []ContributionDay, year int) (*calendarLayout, error) {
+	if year == 0 && design != nil {
+		if design.Window != nil {
+			start, end, err := design.span()
+			if err != nil {
+				return nil, err
+			}
+			counts, _, _, err := countCalendarDays(days)
+			if err != nil {
+				return nil, err
+			}
+			return spanCalendarLayout(counts, start, end), nil
+		}
+		year = design.Year
+	}
+	return newCalendarLayout(days, year)
+}
+
+// countCalendarDays totals the days by date and returns the first and last of them.
+func countCalendarDays(days []ContributionDay) (counts map[string]int, first, last time.Time, err error) {
+	counts = make(map[string]int, len(days))
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return nil, time.Time{}, time.Time{}, fmt.Errorf("invalid contribution date %q", day.Date)
+		}
+		counts[day.Date] += day.Count
+		if first.IsZero() || date.Before(first) {
+			first = date
+		}
+		if last.IsZero() || date.After(last) {
+			last = date
+		}
+	}
+	return counts, first, last, nil
+}
+
+// spanCalendarLayout shows the days from start to end, inclusive.
+func spanCalendarLayout(counts map[string]int, start, end time.Time) *calendarLayout {
+	layout := &calendarLayout{start: start, end: end, counts: counts}
+	layout.startRow = int(layout.start.Weekday())
+	layout.weeks = (layout.dayIndex(layout.end)+layout.startRow)/7 + 1
+	return layout
+}
+
+func (l *calendarLayout) dayIndex(date time.Time) int {
+	return int(date.Sub(l.start).Hours() / 24)
+}
+
+// position returns the column and row of date.
+func (l *calendarLayout) position(date time.Time) (column, row int) {
+	index := l.dayIndex(date) + l.startRow
+	return index / 7, index % 7
+}
+
+// each calls fn for every day shown, in date order.
+func (l *calendarLayout) each(fn func(date time.Time, count int)) {
+	for date := l.start; !date.After(l.end); date = date.AddDate(0, 0, 1) {
+		fn(date, l.counts[date.Format("2006-01-02")])
+	}
+}
+
+// monthLabels names each month above the first week that starts in it, like the editor;
+// a label that would run into its neighbour or off the end is dropped.
+func (l *calendarLayout) monthLabels() []calendarMonthLabel {
+	labels := []calendarMonthLabel{{Column: 0, Name: calendarMonthNames[l.start.Month()-1]}}
+	latest := l.start.Month()
+	l.each(func(date time.Time, _ int) {
+		if date.Weekday() != time.Sunday || date.Month() == latest {
+			return
+		}
+		latest = date.Month()
+		column, _ := l.position(date)
+		labels = append(labels, calendarMonthLabel{Column: column, Name: calendarMonthNames[date.Month()-1]})
+	})
+
+	if len(labels) > 1 && labels[1].Column-labels[0].Column < 3 {
+		labels = labels[1:]
+	}
+	if last := labels[len(labels)-1]; len(labels) > 1 && l.weeks-last.Column < 2 {
+		labels = labels[:len(labels)-1]
+	}
+	return labels
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewCalendarLayoutSpan(t *testing.T) {
+	tests := []struct {
+		name      string
+		days      []ContributionDay
+		year      int
+		wantStart string
+		wantEnd   string
+		wantWeeks int
+	}{
+		{
+			name:      "given year",
+			days:      []ContributionDay{{Date: "2023-05-01", Count: 1}},
+			year:      2024,
+			wantStart: "2024-01-01",
+			wantEnd:   "2024-12-31",
+			wantWeeks: 53,
+		},
+		{
+			name:      "year of the days",
+			days:      []ContributionDay{{Date: "2023-05-01", Count: 1}},
+			wantStart: "2023-01-01",
+			wantEnd:   "2023-12-31",
+			wantWeeks: 53,
+		},
+		{
+			name:      "short span across new year",
+			days:      []ContributionDay{{Date: "2023-12-20", Count: 1}, {Date: "2024-01-10", Count: 1}},
+			wantStart: "2023-12-20",
+			wantEnd:   "2024-01-10",
+			wantWeeks: 4,
+		},
+		{
+			name:      "several years keep the latest weeks",
+			days:      []ContributionDay{{Date: "2021-03-01", Count: 1}, {Date: "2024-06-15", Count: 2}},
+			wantStart: "2023-06-11",
+			wantEnd:   "2024-06-15",
+			wantWeeks: maxCalendarWeeks,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			layout, err := newCalendarLayout(tt.days, tt.year)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start, end := layout.start.Format("2006-01-02"), layout.end.Format("2006-01-02")
+			if start != tt.wantStart || end != tt.wantEnd || layout.weeks != tt.wantWeeks {
+				t.Errorf("layout = %s to %s in %d weeks, want %s to %s in %d", start, end, layout.weeks, tt.wantStart, tt.wantEnd, tt.wantWeeks)
+			}
+		})
+	}
+
+	if _, err := newCalendarLayout(nil, 0); err == nil {
+		t.Error("an empty calendar without a year was accepted")
+	}
+}
+
+func TestNewDesignCalendarLayoutWindow(t *testing.T) {
+	design := &DesignDocument{Version: 1, Window: &DesignWindow{End: "2024-03-23", Weeks: 6}}
+	days := []ContributionDay{{Date: "2024-02-11", Count: 9}}
+
+	layout, err := newDesignCalendarLayout(design, days, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := time.Date(2024, time.February, 11, 0, 0, 0, 0, time.UTC); !layout.start.Equal(want) || layout.weeks != 6 {
+		t.Errorf("layout starts %s with %d weeks, want %s with 6", layout.start, layout.weeks, want)
+	}
+
+	// An explicit year still wins over the window.
+	if layout, err = newDesignCalendarLayout(design, days, 2024); err != nil {
+		t.Fatal(err)
+	}
+	if layout.weeks != 53 {
+		t.Errorf("with a year the layout has %d weeks, want 53", layout.weeks)
+	}
+}
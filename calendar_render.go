@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	calendarFormatSVG = "svg"
+	calendarFormatPNG = "png"
+)
+
+// Calendar geometry in SVG units, the PNG is drawn at the same size times its scale.
+const (
+	calendarCell     = 10
+	calendarGap      = 3
+	calendarStep     = calendarCell + calendarGap
+	calendarRadius   = 2
+	calendarPadding  = 10
+	calendarLeft     = calendarPadding + 26 // room for the weekday labels
+	calendarTop      = calendarPadding + 15 // room for the month labels
+	calendarLegend   = 18                   // height of the legend row below the grid
+	calendarCharW    = 7                    // advance of basicfont.Face7x13, also used to lay out SVG text
+	defaultPNGScale  = 2
+	maxPNGScale      = 8
+	calendarFontSize = 10
+)
+
+var hexColorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// CalendarTheme colours a rendered calendar. Colours are "#rrggbb".
+type CalendarTheme struct {
+	Background string   `json:"background,omitempty"`
+	Text       string   `json:"text,omitempty"`
+	Levels     []string `json:"levels,omitempty"` // five shades, from no contributions to the most
+}
+
+var calendarThemes = map[string]CalendarTheme{
+	"light": {
+		Background: "#ffffff",
+		Text:       "#57606a",
+		Levels:     []string{"#ebedf0", "#9be9a8", "#40c463", "#30a14e", "#216e39"},
+	},
+	"dark": {
+		Background: "#0d1117",
+		Text:       "#8b949e",
+		Levels:     []string{"#161b22", "#0e4429", "#006d32", "#26a641", "#39d353"},
+	},
+}
+
+const defaultCalendarTheme = "light"
+
+type RenderCalendarRequest struct {
+	Contributions []ContributionDay `json:"contributions"`
+	Year          int               `json:"year,omitempty"`        // 0 uses Window or the year of the contributions
+	Window        *DesignWindow     `json:"window,omitempty"`      // the design's rolling window
+	Format        string            `json:"format,omitempty"`      // svg or png; empty follows the file extension
+	Theme         string            `json:"theme,omitempty"`       // light (default) or dark
+	CustomTheme   *CalendarTheme    `json:"customTheme,omitempty"` // overrides colours of Theme
+	Scale         int               `json:"scale,omitempty"`       // PNG pixels per SVG unit, default 2
+}
+
+type RenderCalendarResponse struct {
+	FilePath string `json:"filePath"`
+	Format   string `json:"format"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// ExportCalendarImage renders the contributions as a GitHub-style calendar image.
+func (a *App) ExportCalendarImage(req RenderCalendarRequest) (*RenderCalendarResponse, error) {
+	format := strings.ToLower(strings.TrimSpace(req.Format))
+	if format == "" {
+		format = calendarFormatSVG
+	}
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出日历图片",
+		DefaultFilename: "contributions." + format,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "SVG 图片 (*.svg)", Pattern: "*.svg"},
+			{DisplayName: "PNG 图片 (*.png)", Pattern: "*.png"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+	filePath, req.Format = calendarExportTarget(filePath, format)
+	return writeCalendarImage(filePath, req)
+}
+
+// calendarExportTarget reconciles the path chosen in the save dialog with the requested
+// format: an .svg or .png extension wins, any other path gets the format's extension.
+func calendarExportTarget(filePath, format string) (string, string) {
+	switch ext := strings.ToLower(filepath.Ext(filePath)); ext {
+	case "." + calendarFormatSVG, "." + calendarFormatPNG:
+		return filePath, ext[1:]
+	default:
+		return filePath + "." + format, format
+	}
+}
+
+// calendarFormatForPath picks the image format from a file extension, SVG by default.
+func calendarFormatForPath(path string) string {
+	if strings.EqualFold(filepath.Ext(path), ".png") {
+		return calendarFormatPNG
+	}
+	return calendarFormatSVG
+}
+
+func writeCalendarImage(filePath string, req RenderCalendarRequest) (*RenderCalendarResponse, error) {
+	data, width, height, err := renderCalendar(req)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write calendar image: %w", err)
+	}
+	return &RenderCalendarResponse{FilePath: filePath, Format: strings.ToLower(req.Format), Width: width, Height: height}, nil
+}
+
+// renderCalendar returns the encoded image and its size in pixels. The same request always
+// produces the same bytes.
+func renderCalendar(req RenderCalendarRequest) ([]byte, int, int, error) {
+	theme, err := resolveCalendarTheme(req.Theme, req.CustomTheme)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	layout, err := newDesignCalendarLayout(&DesignDocument{Window: req.Window}, req.Contributions, req.Year)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	switch strings.ToLower(strings.TrimSpace(req.Format)) {
+	case "", calendarFormatSVG:
+		data, width, height := renderCalendarSVG(layout, theme)
+		return data, width, height, nil
+	case calendarFormatPNG:
+		scale := req.Scale
+		if scale == 0 {
+			scale = defaultPNGScale
+		}
+		if scale < 1 || scale > maxPNGScale {
+			return nil, 0, 0, fmt.Errorf("PNG scale must be between 1 and %d", maxPNGScale)
+		}
+		return renderCalendarPNG(layout, theme, scale)
+	default:
+		return nil, 0, 0, fmt.Errorf("unsupported image format %q (use svg or png)", req.Format)
+	}
+}
+
+// resolveCalendarTheme fills the colours custom leaves empty from the named theme.
+func resolveCalendarTheme(name string, custom *CalendarTheme) (CalendarTheme, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" || name == "custom" {
+		name = defaultCalendarTheme
+	}
+	base, ok := calendarThemes[name]
+	if !ok {
+		return CalendarTheme{}, fmt.Errorf("unknown theme %q (use light or dark)", name)
+	}
+	theme := CalendarTheme{Background: base.Background, Text: base.Text, Levels: append([]string(nil), base.Levels...)}
+	if custom != nil {
+		if custom.Background != "" {
+			theme.Background = custom.Background
+		}
+		if custom.Text != "" {
+			theme.Text = custom.Text
+		}
+		if len(custom.Levels) > 0 {
+			if len(custom.Levels) != len(theme.Levels) {
+				return CalendarTheme{}, fmt.Errorf("a theme needs %d level colours, got %d", len(theme.Levels), len(custom.Levels))
+			}
+			theme.Levels = append([]string(nil), custom.Levels...)
+		}
+	}
+
+	for _, value := range append([]string{theme.Background, theme.Text}, theme.Levels...) {
+		if !hexColorPattern.MatchString(value) {
+			return CalendarTheme{}, fmt.Errorf("invalid colour %q: use #rrggbb", value)
+		}
+	}
+	theme.Background = strings.ToLower(theme.Background)
+	theme.Text = strings.ToLower(theme.Text)
+	for i := range theme.Levels {
+		theme.Levels[i] = strings.ToLower(theme.Levels[i])
+	}
+	return theme, nil
+}
+
+// calendarGeometry is where the parts of the calendar go, in SVG units.
+type calendarGeometry struct {
+	width, height int
+	gridBottom    int
+	legendX       int // left edge of "Less"
+	legendY       int // top of the legend squares
+}
+
+func newCalendarGeometry(layout *calendarLayout) calendarGeometry {
+	gridRight := calendarLeft + layout.weeks*calendarStep - calendarGap
+	gridBottom := calendarTop + 7*calendarStep - calendarGap
+	legendWidth := len("Less")*calendarCharW + 4 + 5*calendarStep - calendarGap + 4 + len("More")*calendarCharW
+	return calendarGeometry{
+		width:      gridRight + calendarPadding,
+		height:     gridBottom + calendarLegend + calendarPadding,
+		gridBottom: gridBottom,
+		legendX:    gridRight - legendWidth,
+		legendY:    gridBottom + calendarLegend - calendarCell,
+	}
+}
+
+// calendarWeekdayLabels are drawn on the Monday, Wednesday and Friday rows, as on GitHub.
+var calendarWeekdayLabels = map[int]string{1: "Mon", 3: "Wed", 5: "Fri"}
+
+func renderCalendarSVG(layout *calendarLayout, theme CalendarTheme) ([]byte, int, int) {
+	geo := newCalendarGeometry(layout)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n", geo.width, geo.height, geo.width, geo.height)
+	fmt.Fprintf(&buf, `<rect width="%d" height="%d" fill="%s"/>`+"\n", geo.width, geo.height, theme.Background)
+	fmt.Fprintf(&buf, `<g font-family="-apple-system, BlinkMacSystemFont, 'Segoe UI', Helvetica, Arial, sans-serif" font-size="%d" fill="%s">`+"\n", calendarFontSize, theme.Text)
+	for _, label := range layout.monthLabels() {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d">%s</text>`+"\n", calendarLeft+label.Column*calendarStep, calendarTop-5, label.Name)
+	}
+	for row := 1; row < 7; row += 2 {
+		fmt.Fprintf(&buf, `<text x="%d" y="%d">%s</text>`+"\n", calendarPadding, calendarTop+row*calendarStep+calendarCell-1, calendarWeekdayLabels[row])
+	}
+	fmt.Fprintf(&buf, `<text x="%d" y="%d">Less</text>`+"\n", geo.legendX, geo.legendY+calendarCell-1)
+	fmt.Fprintf(&buf, `<text x="%d" y="%d" text-anchor="end">More</text>`+"\n", geo.width-calendarPadding, geo.legendY+calendarCell-1)
+	buf.WriteString("</g>\n")
+
+	layout.each(func(date time.Time, count int) {
+		column, row := layout.position(date)
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s" data-date="%s" data-count="%d"><title>%s</title></rect>`+"\n",
+			calendarLeft+column*calendarStep, calendarTop+row*calendarStep, calendarCell, calendarCell, calendarRadius,
+			theme.Levels[contributionLevel(count)], date.Format("2006-01-02"), count, calendarTooltip(date, count))
+	})
+	for level, x := 0, calendarLegendSquaresX(geo); level < len(theme.Levels); level, x = level+1, x+calendarStep {
+		fmt.Fprintf(&buf, `<rect x="%d" y="%d" width="%d" height="%d" rx="%d" fill="%s"/>`+"\n", x, geo.legendY, calendarCell, calendarCell, calendarRadius, theme.Levels[level])
+	}
+	buf.WriteString("</svg>\n")
+	return buf.Bytes(), geo.width, geo.height
+}
+
+func calendarLegendSquaresX(geo calendarGeometry) int {
+	return geo.legendX + len("Less")*calendarCharW + 4
+}
+
+func calendarTooltip(date time.Time, count int) string {
+	switch count {
+	case 0:
+		return "No contributions on " + date.Format("Jan 2, 2006")
+	case 1:
+		return "1 contribution on " + date.Format("Jan 2, 2006")
+	default:
+		return fmt.Sprintf("%d contributions on %s", count, date.Format("Jan 2, 2006"))
+	}
+}
+
+func renderCalendarPNG(layout *calendarLayout, theme CalendarTheme, scale int) ([]byte, int, int, error) {
+	geo := newCalendarGeometry(layout)
+	img := image.NewRGBA(image.Rect(0, 0, geo.width*scale, geo.height*scale))
+	fillRect(img, img.Bounds(), parseHexColor(theme.Background))
+
+	text := parseHexColor(theme.Text)
+	for _, label := range layout.monthLabels() {
+		drawCalendarText(img, calendarLeft+label.Column*calendarStep, calendarTop-5, label.Name, text, scale)
+	}
+	for row := 1; row < 7; row += 2 {
+		drawCalendarText(img, calendarPadding, calendarTop+row*calendarStep+calendarCell-1, calendarWeekdayLabels[row], text, scale)
+	}
+	drawCalendarText(img, geo.legendX, geo.legendY+calendarCell-1, "Less", text, scale)
+	drawCalendarText(img, geo.width-calendarPadding-len("More")*calendarCharW, geo.legendY+calendarCell-1, "More", text, scale)
+
+	levels := make([]color.RGBA, len(theme.Levels))
+	for i, value := range theme.Levels {
+		levels[i] = parseHexColor(value)
+	}
+	layout.each(func(date time.Time, count int) {
+		column, row := layout.position(date)
+		fillRoundedRect(img, calendarLeft+column*calendarStep, calendarTop+row*calendarStep, levels[contributionLevel(count)], scale)
+	})
+	for level, x := 0, calendarLegendSquaresX(geo); level < len(levels); level, x = level+1, x+calendarStep {
+		fillRoundedRect(img, x, geo.legendY, levels[level], scale)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, 0, 0, fmt.Errorf("encode PNG: %w", err)
+	}
+	return buf.Bytes(), img.Bounds().Dx(), img.Bounds().Dy(), nil
+}
+
+func parseHexColor(value string) color.RGBA {
+	var r, g, b uint8
+	fmt.Sscanf(value, "#%02x%02x%02x", &r, &g, &b)
+	return color.RGBA{R: r, G: g, B: b, A: 0xff}
+}
+
+func fillRect(img *image.RGBA, rect image.Rectangle, c color.RGBA) {
+	rect = rect.Intersect(img.Bounds())
+	for y := rect.Min.Y; y < rect.Max.Y; y++ {
+		for x := rect.Min.X; x < rect.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// fillRoundedRect draws one calendar cell with its top-left corner at (x, y) in SVG units.
+// Pixels are kept when their centre lies inside the rounded shape, so there is no
+// anti-aliasing to vary between platforms.
+func fillRoundedRect(img *image.RGBA, x, y int, c color.RGBA, scale int) {
+	size := float64(calendarCell * scale)
+	radius := float64(calendarRadius * scale)
+	for py := 0; py < calendarCell*scale; py++ {
+		for px := 0; px < calendarCell*scale; px++ {
+			cx, cy := float64(px)+0.5, float64(py)+0.5
+			dx := maxFloat(radius-cx, cx-(size-radius))
+			dy := maxFloat(radius-cy, cy-(size-radius))
+			if dx > 0 && dy > 0 && dx*dx+dy*dy > radius*radius {
+				continue
+			}
+			img.SetRGBA(x*scale+px, y*scale+py, c)
+		}
+	}
+}
+
+func maxFloat(a, b float64) float64 {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// drawCalendarText draws s with its baseline at (x, y) in SVG units. The bitmap font is
+// drawn once and each pixel enlarged to scale, keeping the output identical everywhere.
+func drawCalendarText(img *image.RGBA, x, y int, s string, c color.RGBA, scale int) {
+	face := basicfont.Face7x13
+	mask := image.NewAlpha(image.Rect(0, 0, len(s)*calendarCharW, face.Height))
+	drawer := font.Drawer{Dst: mask, Src: image.Opaque, Face: face, Dot: fixed.P(0, face.Ascent)}
+	drawer.DrawString(s)
+
+	top := y - face.Ascent
+	for py := 0; py < mask.Bounds().Dy(); py++ {
+		for px := 0; px < mask.Bounds().Dx(); px++ {
+			if mask.AlphaAt(px, py).A < 0x80 {
+				continue
+			}
+			fillRect(img, image.Rect((x+px)*scale, (top+py)*scale, (x+px+1)*scale, (top+py+1)*scale), c)
+		}
+	}
+}
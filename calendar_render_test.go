@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"image"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// goldenCalendar has a day at every level, around the turn of the months.
+var goldenCalendar = []ContributionDay{
+	{Date: "2024-01-01", Count: 1},
+	{Date: "2024-01-31", Count: 3},
+	{Date: "2024-02-29", Count: 6},
+	{Date: "2024-06-15", Count: 9},
+	{Date: "2024-06-16", Count: 12},
+	{Date: "2024-12-31", Count: 2},
+}
+
+// checkGolden compares got with testdata/name, or rewrites the file with -update.
+func checkGolden(t *testing.T, name string, got []byte, equal func(want, got []byte) bool) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *updateGolden {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run go test -update to create it)", err)
+	}
+	if !equal(want, got) {
+		t.Errorf("%s differs from the rendered calendar (run go test -update if the change is intended)", path)
+	}
+}
+
+// samePixels compares decoded PNGs, so a different zlib in a new Go release doesn't matter.
+func samePixels(t *testing.T) func(want, got []byte) bool {
+	return func(want, got []byte) bool {
+		decode := func(data []byte) image.Image {
+			img, err := png.Decode(bytes.NewReader(data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			return img
+		}
+		a, b := decode(want), decode(got)
+		if a.Bounds() != b.Bounds() {
+			return false
+		}
+		for y := a.Bounds().Min.Y; y < a.Bounds().Max.Y; y++ {
+			for x := a.Bounds().Min.X; x < a.Bounds().Max.X; x++ {
+				r1, g1, b1, a1 := a.At(x, y).RGBA()
+				r2, g2, b2, a2 := b.At(x, y).RGBA()
+				if r1 != r2 || g1 != g2 || b1 != b2 || a1 != a2 {
+					return false
+				}
+			}
+		}
+		return true
+	}
+}
+
+func TestRenderCalendarGolden(t *testing.T) {
+	for _, theme := range []string{"light", "dark"} {
+		for _, format := range []string{calendarFormatSVG, calendarFormatPNG} {
+			t.Run(theme+"."+format, func(t *testing.T) {
+				req := RenderCalendarRequest{Contributions: goldenCalendar, Year: 2024, Format: format, Theme: theme, Scale: 1}
+				data, width, height, err := renderCalendar(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+				again, _, _, err := renderCalendar(req)
+				if err != nil || !bytes.Equal(data, again) {
+					t.Fatalf("rendering twice gave different output (%v)", err)
+				}
+
+				equal := bytes.Equal
+				if format == calendarFormatPNG {
+					equal = samePixels(t)
+					config, err := png.DecodeConfig(bytes.NewReader(data))
+					if err != nil {
+						t.Fatal(err)
+					}
+					if config.Width != width || config.Height != height {
+						t.Errorf("image is %dx%d, reported %dx%d", config.Width, config.Height, width, height)
+					}
+				}
+				checkGolden(t, "calendar_"+theme+"."+format, data, equal)
+			})
+		}
+	}
+}
+
+func TestRenderCalendarUsesTheDesignWindow(t *testing.T) {
+	days := []ContributionDay{{Date: "2024-06-01", Count: 3}}
+	_, yearWidth, _, err := renderCalendar(RenderCalendarRequest{Contributions: days, Format: calendarFormatSVG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	window := &DesignWindow{End: "2024-06-15", Weeks: 4}
+	_, width, _, err := renderCalendar(RenderCalendarRequest{Contributions: days, Window: window, Format: calendarFormatSVG})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := yearWidth - (53-4)*calendarStep; width != want {
+		t.Errorf("width of a 4-week window = %d, want %d", width, want)
+	}
+}
+
+func TestCalendarExportTarget(t *testing.T) {
+	tests := []struct {
+		path, format      string
+		wantPath, wantFmt string
+	}{
+		{"wall.png", calendarFormatSVG, "wall.png", calendarFormatPNG},
+		{"wall.SVG", calendarFormatPNG, "wall.SVG", calendarFormatSVG},
+		{"wall", calendarFormatPNG, "wall.png", calendarFormatPNG},
+		{"wall.jpg", calendarFormatSVG, "wall.jpg.svg", calendarFormatSVG},
+	}
+	for _, tt := range tests {
+		path, format := calendarExportTarget(tt.path, tt.format)
+		if path != tt.wantPath || format != tt.wantFmt {
+			t.Errorf("calendarExportTarget(%q, %q) = %q, %q, want %q, %q", tt.path, tt.format, path, format, tt.wantPath, tt.wantFmt)
+		}
+	}
+}
+
+func TestResolveCalendarTheme(t *testing.T) {
+	theme, err := resolveCalendarTheme("dark", &CalendarTheme{Background: "#FFFFFF"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if theme.Background != "#ffffff" || theme.Text != calendarThemes["dark"].Text || len(theme.Levels) != 5 {
+		t.Errorf("theme = %+v, want the dark theme on a white background", theme)
+	}
+
+	invalid := []struct {
+		name   string
+		custom *CalendarTheme
+	}{
+		{name: "sepia"},
+		{name: "light", custom: &CalendarTheme{Background: "white"}},
+		{name: "dark", custom: &CalendarTheme{Text: "#12345"}},
+		{name: "custom", custom: &CalendarTheme{Levels: []string{"#000000"}}},
+		{name: "custom", custom: &CalendarTheme{Levels: []string{"#000000", "#111111", "#222222", "#333333", "#44444g"}}},
+	}
+	for _, tt := range invalid {
+		if _, err := resolveCalendarTheme(tt.name, tt.custom); err == nil {
+			t.Errorf("resolveCalendarTheme(%q, %+v) was accepted", tt.name, tt.custom)
+		}
+	}
+}
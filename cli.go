@@ -0,0 +1,265 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// cliCommands run without opening the window, for scripts and CI.
+var cliCommands = map[string]func(args []string, stdout, stderr io.Writer) error{
+	"render":  runRenderCommand,
+	"image":   runImageCommand,
+	"preview": runPreviewCommand,
+	"grid":    runGridCommand,
+}
+
+// runCLI runs a command-line subcommand. It reports false when args don't name one, in
+// which case the app starts normally.
+func runCLI(args []string, stdout, stderr io.Writer) (handled bool, exitCode int) {
+	if len(args) == 0 {
+		return false, 0
+	}
+	command, ok := cliCommands[args[0]]
+	if !ok {
+		return false, 0
+	}
+	if err := command(args[1:], stdout, stderr); err != nil {
+		if err == flag.ErrHelp {
+			return true, 0
+		}
+		fmt.Fprintf(stderr, "green-wall %s: %v\n", args[0], err)
+		return true, 1
+	}
+	return true, 0
+}
+
+func runRenderCommand(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: green-wall render -in design.greenwall -out calendar.svg|calendar.png [options]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "", "design (.greenwall), text grid or contributions file exported from the app")
+	out := flags.String("out", "", "image to write; the extension picks SVG or PNG unless -format is set")
+	format := flags.String("format", "", "svg or png")
+	theme := flags.String("theme", defaultCalendarTheme, "light or dark")
+	levels := flags.String("levels", "", "five comma-separated #rrggbb colours replacing the theme's shades")
+	background := flags.String("background", "", "#rrggbb background replacing the theme's")
+	text := flags.String("text", "", "#rrggbb label colour replacing the theme's")
+	year := flags.Int("year", 0, "year to draw; 0 uses the design's year or window, or that of the contributions")
+	scale := flags.Int("scale", defaultPNGScale, "PNG pixels per SVG unit")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		flags.Usage()
+		return fmt.Errorf("both -in and -out are required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return fmt.Errorf("read contributions file: %w", err)
+	}
+	design, contributions, err := decodeDesign(data)
+	if err != nil {
+		return err
+	}
+	req := RenderCalendarRequest{
+		Contributions: contributions,
+		Year:          *year,
+		Format:        *format,
+		Theme:         *theme,
+		Scale:         *scale,
+	}
+	if req.Format == "" {
+		req.Format = calendarFormatForPath(*out)
+	}
+	if *year == 0 && design != nil {
+		req.Year, req.Window = design.Year, design.Window
+	}
+	if *levels != "" || *background != "" || *text != "" {
+		req.CustomTheme = &CalendarTheme{Background: *background, Text: *text}
+		if *levels != "" {
+			for _, level := range strings.Split(*levels, ",") {
+				req.CustomTheme.Levels = append(req.CustomTheme.Levels, strings.TrimSpace(level))
+			}
+		}
+	}
+
+	resp, err := writeCalendarImage(*out, req)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "wrote %s (%dx%d %s)\n", resp.FilePath, resp.Width, resp.Height, strings.ToUpper(resp.Format))
+	return nil
+}
+
+func runImageCommand(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("image", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: green-wall image -in picture.png -out contributions.json [options]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "", "PNG, JPEG or GIF image")
+	out := flags.String("out", "", "contributions file to write, readable by the app's import")
+	width := flags.Int("width", 0, "weeks the image spans, 1-52; 0 follows the aspect ratio")
+	height := flags.Int("height", 0, "days the image spans, 1-7; 0 follows the aspect ratio")
+	mode := flags.String("mode", imageModeAuto, "auto or binary")
+	keep := flags.Bool("no-invert", false, "turn bright pixels into contributions instead of dark ones")
+	threshold := flags.Int("threshold", 0, "treat brightness at or below this as empty, 0-255")
+	nearest := flags.Bool("nearest", false, "sample single pixels instead of averaging areas")
+	recovery := flags.Int("stroke-recovery", defaultStrokeRecovery, "binary mode: lower the threshold by this much when the result is sparse")
+	secondary := flags.Int("secondary-recovery", 0, "binary mode: further lowering after stroke recovery")
+	dilation := flags.Int("dilation", 0, "thicken strokes by this many cells, 0-3")
+	dither := flags.String("dither", imageDitherNone, "auto mode: none, floyd-steinberg or ordered")
+	year := flags.Int("year", 0, "year to place the image in; 0 is the current year")
+	column := flags.Int("column", 0, "week column of the image's left edge, 0-52")
+	row := flags.Int("row", 0, "weekday row of the image's top edge, 0 is Sunday")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" || *out == "" {
+		flags.Usage()
+		return fmt.Errorf("both -in and -out are required")
+	}
+
+	invert := !*keep
+	resp, err := importImageFile(ImageImportRequest{
+		FilePath:          *in,
+		Width:             *width,
+		Height:            *height,
+		Mode:              *mode,
+		Invert:            &invert,
+		Threshold:         *threshold,
+		Nearest:           *nearest,
+		StrokeRecovery:    recovery,
+		SecondaryRecovery: *secondary,
+		Dilation:          *dilation,
+		Dither:            *dither,
+		Year:              *year,
+		Column:            *column,
+		Row:               *row,
+	}, time.Now())
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(resp.Contributions, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal contributions: %w", err)
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		return fmt.Errorf("write contributions to file: %w", err)
+	}
+	fmt.Fprintf(stdout, "wrote %s (%dx%d grid, %d days", *out, resp.Width, resp.Height, len(resp.Contributions))
+	if resp.SkippedCells > 0 {
+		fmt.Fprintf(stdout, ", %d cells outside the year or in the future skipped", resp.SkippedCells)
+	}
+	fmt.Fprintln(stdout, ")")
+	return nil
+}
+
+func runPreviewCommand(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("preview", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: green-wall preview -in design.greenwall [options]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "", "design (.greenwall), text grid or contributions file exported from the app")
+	color := flags.String("color", "auto", "auto, none (plain ASCII), 256 or truecolor")
+	theme := flags.String("theme", defaultCalendarTheme, "light or dark")
+	year := flags.Int("year", 0, "year to draw; 0 uses the design's year or window, or the year (at most a year) the contributions cover")
+	plan := flags.Bool("plan", false, "also summarise the commits generating the design would create")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		flags.Usage()
+		return fmt.Errorf("-in is required")
+	}
+
+	opts := terminalPreviewOptions{Color: strings.ToLower(*color)}
+	switch opts.Color {
+	case "auto":
+		opts.Color = detectTerminalColor(stdout)
+	case terminalColorNone, terminalColor256, terminalColorTrueColor:
+	default:
+		return fmt.Errorf("unsupported colour mode %q (use auto, none, 256 or truecolor)", *color)
+	}
+	var err error
+	if opts.Theme, err = resolveCalendarTheme(*theme, nil); err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return fmt.Errorf("read contributions file: %w", err)
+	}
+	design, contributions, err := decodeDesign(data)
+	if err != nil {
+		return err
+	}
+	layout, err := newDesignCalendarLayout(design, contributions, *year)
+	if err != nil {
+		return err
+	}
+
+	if design != nil && design.Title != "" {
+		fmt.Fprintln(stdout, design.Title)
+	}
+	fmt.Fprint(stdout, renderTerminalCalendar(layout, opts))
+	if *plan {
+		fmt.Fprint(stdout, calendarPlanSummary(contributions))
+	}
+	return nil
+}
+
+func runGridCommand(args []string, stdout, stderr io.Writer) error {
+	flags := flag.NewFlagSet("grid", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: green-wall grid -in design.greenwall [-out design.grid.txt] [options]")
+		flags.PrintDefaults()
+	}
+	in := flags.String("in", "", "design (.greenwall), text grid or contributions file exported from the app")
+	out := flags.String("out", "", "text grid to write; empty prints it")
+	year := flags.Int("year", 0, "year to draw; 0 uses the design's year or window, or that of the contributions")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *in == "" {
+		flags.Usage()
+		return fmt.Errorf("-in is required")
+	}
+
+	data, err := os.ReadFile(*in)
+	if err != nil {
+		return fmt.Errorf("read contributions file: %w", err)
+	}
+	design, contributions, err := decodeDesign(data)
+	if err != nil {
+		return err
+	}
+	text, err := encodeContributionGrid(design, contributions, *year)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err := io.WriteString(stdout, text)
+		return err
+	}
+	if err := os.WriteFile(*out, []byte(text), 0o644); err != nil {
+		return fmt.Errorf("write grid to file: %w", err)
+	}
+	fmt.Fprintf(stdout, "wrote %s\n", *out)
+	return nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunPreviewCommandWindowDesign(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "window.greenwall")
+	design := `{"version":1,"title":"Window","window":{"end":"2024-03-23","weeks":6},"days":[
+		{"date":"2024-02-11","level":4},
+		{"date":"2024-02-29","count":3},
+		{"date":"2024-03-23","count":1}
+	]}`
+	if err := os.WriteFile(path, []byte(design), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := runPreviewCommand([]string{"-in", path, "-color", "none"}, &stdout, &stderr); err != nil {
+		t.Fatalf("%v\n%s", err, stderr.String())
+	}
+	// The six weeks of the window, not the whole of 2024.
+	want := `Window
+    Feb   Mar
+    # . . . . .
+Mon . . . . . .
+    . . . . . .
+Wed . . . . . .
+    . . o . . .
+Fri . . . . . .
+    . . . . . :
+    Less . 0 : 1-2 o 3-5 O 6-8 # 9+ More
+`
+	if got := stdout.String(); got != want {
+		t.Errorf("preview:\n%s\nwant:\n%s", got, want)
+	}
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Ways of merging several entries for the same day on import.
+const (
+	duplicateKeepLast  = "last" // what importing did before validation existed
+	duplicateKeepFirst = "first"
+	duplicateSum       = "sum"
+	duplicateMax       = "max"
+	duplicateReject    = "error"
+)
+
+const (
+	maxReportedImportErrors = 50
+	importErrorsInMessage   = 5
+)
+
+type importValidationOptions struct {
+	Year       int    // entries of a contributions array outside this year are left out; 0 keeps every year
+	Duplicates string // one of the duplicate* policies; empty keeps the last entry
+}
+
+// ImportIssue is one problem found in an imported file. Index is the 0-based position of
+// the entry in the contributions array, or -1 for the file as a whole; Offset is the byte
+// offset of the entry, or of the syntax error, in the file.
+type ImportIssue struct {
+	Index   int    `json:"index"`
+	Offset  int64  `json:"offset"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Date    string `json:"date,omitempty"`
+	Message string `json:"message"`
+}
+
+func (issue ImportIssue) String() string {
+	location := fmt.Sprintf("line %d, column %d (offset %d)", issue.Line, issue.Column, issue.Offset)
+	if issue.Index >= 0 {
+		location = fmt.Sprintf("entry %d at %s", issue.Index, location)
+	}
+	return location + ": " + issue.Message
+}
+
+// ImportReport summarises what validation found and changed.
+type ImportReport struct {
+	Entries  int           `json:"entries"`  // entries in the file
+	Accepted int           `json:"accepted"` // days imported after merging and dropping
+	Warnings []ImportIssue `json:"warnings"` // entries left out
+	Fixes    []ImportIssue `json:"fixes"`    // entries merged or tidied
+}
+
+// ImportValidationError lists the entries that stopped an import.
+type ImportValidationError struct {
+	Issues []ImportIssue
+}
+
+func (e *ImportValidationError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "the file has %d problem(s): ", len(e.Issues))
+	for i, issue := range e.Issues {
+		if i == importErrorsInMessage {
+			fmt.Fprintf(&b, "; and %d more", len(e.Issues)-i)
+			break
+		}
+		if i > 0 {
+			b.WriteString("; ")
+		}
+		b.WriteString(issue.String())
+	}
+	return b.String()
+}
+
+// importEntry is one validated day before duplicates are merged.
+type importEntry struct {
+	issue ImportIssue // locates the entry
+	day   DesignDay   // as written
+	count int         // the commits the day stands for
+}
+
+type contributionsValidator struct {
+	data    []byte
+	offset  int64 // bytes stripped from the front of data, such as a BOM
+	opts    importValidationOptions
+	report  *ImportReport
+	errs    []ImportIssue
+	entries []importEntry
+}
+
+// validateContributionsFile decodes a .greenwall document, a bare contributions array or a
+// text grid and checks every entry, reporting problems with their position in the file.
+// Entries that can't be imported fail the import with an *ImportValidationError; duplicates
+// are merged by opts.Duplicates and the rest is described in the report.
+func validateContributionsFile(data []byte, opts importValidationOptions) (*DesignDocument, []ContributionDay, *ImportReport, error) {
+	switch opts.Duplicates = strings.ToLower(strings.TrimSpace(opts.Duplicates)); opts.Duplicates {
+	case "":
+		opts.Duplicates = duplicateKeepLast
+	case duplicateKeepLast, duplicateKeepFirst, duplicateSum, duplicateMax, duplicateReject:
+	default:
+		return nil, nil, nil, fmt.Errorf("unsupported duplicate policy %q (use last, first, sum, max or error)", opts.Duplicates)
+	}
+
+	v := &contributionsValidator{
+		data:   bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")),
+		opts:   opts,
+		report: &ImportReport{Warnings: []ImportIssue{}, Fixes: []ImportIssue{}},
+	}
+	v.offset = int64(len(data) - len(v.data))
+
+	var doc *DesignDocument
+	switch first := bytes.TrimLeft(v.data, " \t\r\n"); {
+	case len(first) == 0:
+		return nil, nil, nil, fmt.Errorf("the file is empty")
+	case first[0] == '[':
+		if err := v.scanEntries(json.NewDecoder(bytes.NewReader(v.data)), false, time.Time{}, time.Time{}); err != nil {
+			return nil, nil, nil, err
+		}
+	case first[0] == '{':
+		// Designs and grids bring their own dates, which may run into another year.
+		v.opts.Year = 0
+		var err error
+		if doc, err = v.scanDocument(); err != nil {
+			return nil, nil, nil, err
+		}
+	case bytes.HasPrefix(first, []byte(gridMagic)):
+		v.opts.Year = 0
+		if err := v.scanGrid(int64(len(v.data) - len(first))); err != nil {
+			return nil, nil, nil, err
+		}
+	default:
+		return nil, nil, nil, &ImportValidationError{Issues: []ImportIssue{v.issue(-1, int64(len(v.data)-len(first)), "expected a contributions array, a GreenWall design or a grid")}}
+	}
+	if len(v.errs) > 0 {
+		return nil, nil, nil, &ImportValidationError{Issues: v.errs}
+	}
+
+	contributions, err := v.merge()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	v.report.Accepted = len(contributions)
+	return doc, contributions, v.report, nil
+}
+
+// scanDocument decodes the design's header, then walks to its days so each one can be
+// located in the file.
+func (v *contributionsValidator) scanDocument() (*DesignDocument, error) {
+	doc := &DesignDocument{}
+	header := struct {
+		*DesignDocument
+		Days json.RawMessage `json:"days"` // checked entry by entry below
+	}{DesignDocument: doc}
+	if err := json.Unmarshal(v.data, &header); err != nil {
+		return nil, v.decodeError(err)
+	}
+	start, end, err := doc.checkHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(v.data))
+	if _, err := decoder.Token(); err != nil {
+		return nil, v.decodeError(err)
+	}
+	for decoder.More() {
+		key, err := decoder.Token()
+		if err != nil {
+			return nil, v.decodeError(err)
+		}
+		if key == "days" {
+			if err := v.scanEntries(decoder, true, start, end); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		var skip json.RawMessage
+		if err := decoder.Decode(&skip); err != nil {
+			return nil, v.decodeError(err)
+		}
+	}
+
+	doc.Days = make([]DesignDay, 0, len(v.entries))
+	for _, entry := range v.entries {
+		doc.Days = append(doc.Days, entry.day)
+	}
+	return doc, nil
+}
+
+// scanEntries reads the array at the decoder's position. Levels are only allowed in designs,
+// whose days must fall within start and end.
+func (v *contributionsValidator) scanEntries(decoder *json.Decoder, design bool, start, end time.Time) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return v.decodeError(err)
+	}
+	if token == nil {
+		return nil // "days": null
+	}
+	if delim, ok := token.(json.Delim); !ok || delim != '[' {
+		return &ImportValidationError{Issues: []ImportIssue{v.issue(-1, decoder.InputOffset(), "expected an array of days")}}
+	}
+
+	for index := 0; decoder.More(); index++ {
+		entry := importEntry{issue: v.issue(index, v.skipSeparators(decoder.InputOffset()), "")}
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			return v.decodeError(err)
+		}
+		v.report.Entries++
+		if problem := v.checkEntry(&entry, raw, design, start, end); problem != "" {
+			if len(v.errs) < maxReportedImportErrors {
+				issue := entry.issue
+				issue.Message = problem
+				v.errs = append(v.errs, issue)
+			}
+			continue
+		}
+		v.entries = append(v.entries, entry)
+	}
+	if _, err := decoder.Token(); err != nil {
+		return v.decodeError(err)
+	}
+	return nil
+}
+
+// checkEntry fills in entry from raw, returning why it can't be imported if it can't.
+func (v *contributionsValidator) checkEntry(entry *importEntry, raw json.RawMessage, design bool, start, end time.Time) string {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil || fields == nil {
+		return "expected an object with a date and a count"
+	}
+
+	dateValue, ok := fields["date"]
+	if !ok {
+		return "the date is missing"
+	}
+	if err := json.Unmarshal(dateValue, &entry.day.Date); err != nil {
+		return "the date must be a string"
+	}
+	entry.issue.Date = entry.day.Date
+	if _, err := time.Parse("2006-01-02", entry.day.Date); err != nil {
+		return fmt.Sprintf("invalid date %q: use an existing date as YYYY-MM-DD", entry.day.Date)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		var problem string
+		switch {
+		case key == "date":
+		case key == "count":
+			entry.day.Count, problem = parseImportInteger(key, fields[key])
+		case key == "level" && design:
+			entry.day.Level, problem = parseImportInteger(key, fields[key])
+		default:
+			v.fix(*entry, fmt.Sprintf("ignored the unknown field %q", key))
+		}
+		if problem != "" {
+			return problem
+		}
+	}
+
+	count, err := designDayCount(entry.day, start, end)
+	if err != nil {
+		return err.Error()
+	}
+	entry.count = count
+	return ""
+}
+
+// parseImportInteger reads a count or level, accepting whole numbers written as floats
+// such as 3.0 or 1e3.
+func parseImportInteger(name string, raw json.RawMessage) (int, string) {
+	text := strings.TrimSpace(string(raw))
+	if value, err := strconv.Atoi(text); err == nil {
+		return value, ""
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	switch {
+	case err != nil && !errors.Is(err, strconv.ErrRange):
+		return 0, fmt.Sprintf("the %s must be a number, not %s", name, text)
+	case math.Abs(value) >= math.MaxInt:
+		return 0, fmt.Sprintf("the %s %s is too large", name, text)
+	case err != nil || value != math.Trunc(value): // err: too small to be a float
+		return 0, fmt.Sprintf("the %s must be a whole number, not %s", name, text)
+	}
+	return int(value), ""
+}
+
+// merge applies the duplicate policy, drops what can't be drawn and sorts the days.
+func (v *contributionsValidator) merge() ([]ContributionDay, error) {
+	seen := make(map[string]int, len(v.entries))
+	var days []importEntry
+	var duplicates []ImportIssue
+	for _, entry := range v.entries {
+		if v.opts.Year > 0 && !strings.HasPrefix(entry.day.Date, strconv.Itoa(v.opts.Year)+"-") {
+			v.warn(entry, fmt.Sprintf("left out %s, which is not in %d", entry.day.Date, v.opts.Year))
+			continue
+		}
+		previous, ok := seen[entry.day.Date]
+		if !ok {
+			seen[entry.day.Date] = len(days)
+			days = append(days, entry)
+			continue
+		}
+
+		kept := &days[previous]
+		switch v.opts.Duplicates {
+		case duplicateReject:
+			issue := entry.issue
+			issue.Message = fmt.Sprintf("%s already appears as entry %d", entry.day.Date, kept.issue.Index)
+			duplicates = append(duplicates, issue)
+			continue
+		case duplicateKeepFirst:
+			v.fix(entry, fmt.Sprintf("kept entry %d for %s and left this one out", kept.issue.Index, entry.day.Date))
+		case duplicateSum:
+			kept.count += entry.count
+			v.fix(entry, fmt.Sprintf("added %d to entry %d for %s", entry.count, kept.issue.Index, entry.day.Date))
+		case duplicateMax:
+			if entry.count > kept.count {
+				kept.count = entry.count
+			}
+			v.fix(entry, fmt.Sprintf("kept the larger count of this and entry %d for %s", kept.issue.Index, entry.day.Date))
+		default:
+			v.fix(entry, fmt.Sprintf("replaced entry %d for %s", kept.issue.Index, entry.day.Date))
+			kept.count = entry.count
+		}
+	}
+	if len(duplicates) > 0 {
+		return nil, &ImportValidationError{Issues: duplicates}
+	}
+
+	contributions := make([]ContributionDay, 0, len(days))
+	for _, entry := range days {
+		if entry.count == 0 {
+			v.fix(entry, fmt.Sprintf("left out %s, which has no contributions", entry.day.Date))
+			continue
+		}
+		contributions = append(contributions, ContributionDay{Date: entry.day.Date, Count: entry.count})
+	}
+	sort.SliceStable(contributions, func(i, j int) bool { return contributions[i].Date < contributions[j].Date })
+	return contributions, nil
+}
+
+func (v *contributionsValidator) warn(entry importEntry, message string) {
+	issue := entry.issue
+	issue.Message = message
+	v.report.Warnings = append(v.report.Warnings, issue)
+}
+
+func (v *contributionsValidator) fix(entry importEntry, message string) {
+	issue := entry.issue
+	issue.Message = message
+	v.report.Fixes = append(v.report.Fixes, issue)
+}
+
+// decodeError locates a JSON syntax or type error in the file.
+func (v *contributionsValidator) decodeError(err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		return &ImportValidationError{Issues: []ImportIssue{v.issue(-1, syntaxErr.Offset, "invalid JSON: "+syntaxErr.Error())}}
+	case errors.As(err, &typeErr):
+		message := fmt.Sprintf("%s must be %s, not %s", typeErr.Field, typeErr.Type, typeErr.Value)
+		return &ImportValidationError{Issues: []ImportIssue{v.issue(-1, typeErr.Offset, message)}}
+	case errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF):
+		return &ImportValidationError{Issues: []ImportIssue{v.issue(-1, int64(len(v.data)), "the file ends unexpectedly")}}
+	default:
+		return fmt.Errorf("decode contributions: %w", err)
+	}
+}
+
+// skipSeparators moves an offset past the whitespace and comma before the next value.
+func (v *contributionsValidator) skipSeparators(offset int64) int64 {
+	for offset < int64(len(v.data)) && strings.IndexByte(" \t\r\n,", v.data[offset]) >= 0 {
+		offset++
+	}
+	return offset
+}
+
+// issue builds an issue at offset within the data, reported as an offset into the file.
+func (v *contributionsValidator) issue(index int, offset int64, message string) ImportIssue {
+	if offset > int64(len(v.data)) {
+		offset = int64(len(v.data))
+	}
+	line, column := 1, 1
+	for _, b := range v.data[:offset] {
+		switch {
+		case b == '\n':
+			line, column = line+1, 1
+		case utf8.RuneStart(b):
+			column++
+		}
+	}
+	return ImportIssue{Index: index, Offset: offset + v.offset, Line: line, Column: column, Message: message}
+}
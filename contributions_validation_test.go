@@ -0,0 +1,173 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateContributionsFile(t *testing.T) {
+	grid := "greenwall-grid 1\nanchor 2023-12-31\n#2\n..\n..\n..\n..\n..\n..\n"
+	tests := []struct {
+		name         string
+		data         string
+		opts         importValidationOptions
+		want         []ContributionDay
+		wantWarnings int
+		wantFixes    int
+		wantErr      string // part of the error; empty expects success
+	}{
+		{
+			name: "array",
+			data: `[{"date":"2024-01-02","count":2},{"date":"2024-01-01","count":1}]`,
+			want: []ContributionDay{{Date: "2024-01-01", Count: 1}, {Date: "2024-01-02", Count: 2}},
+		},
+		{
+			name: "whole numbers written as floats",
+			data: `[{"date":"2024-01-01","count":1e3},{"date":"2024-01-02","count":3.0},{"date":"2024-01-03","count":-0.0}]`,
+			want: []ContributionDay{{Date: "2024-01-01", Count: 1000}, {Date: "2024-01-02", Count: 3}},
+			// the zero day is left out
+			wantFixes: 1,
+		},
+		{
+			name:    "fraction",
+			data:    "[\n  {\"date\":\"2024-01-01\",\"count\":1.5}\n]",
+			wantErr: "entry 0 at line 2, column 3 (offset 4): the count must be a whole number, not 1.5",
+		},
+		{
+			name:    "count too large",
+			data:    `[{"date":"2024-01-01","count":1e300}]`,
+			wantErr: "the count 1e300 is too large",
+		},
+		{
+			name:    "count that is not a number",
+			data:    `[{"date":"2024-01-01","count":"3"}]`,
+			wantErr: `the count must be a number, not "3"`,
+		},
+		{
+			name:    "invalid date",
+			data:    `[{"date":"2024-02-30","count":1}]`,
+			wantErr: `invalid date "2024-02-30"`,
+		},
+		{
+			name:    "syntax error",
+			data:    "\xef\xbb\xbf[{\"date\":\"2024-01-01\",\"count\":1}\n{}]",
+			wantErr: "line 2, column 2 (offset 37): invalid JSON", // the offset counts the BOM
+		},
+		{
+			name:      "unknown field",
+			data:      `[{"date":"2024-01-01","count":1,"note":"x"}]`,
+			want:      []ContributionDay{{Date: "2024-01-01", Count: 1}},
+			wantFixes: 1,
+		},
+		{
+			name:      "duplicates keep the last entry by default",
+			data:      `[{"date":"2024-01-01","count":1},{"date":"2024-01-01","count":4},{"date":"2024-01-01","count":2}]`,
+			want:      []ContributionDay{{Date: "2024-01-01", Count: 2}},
+			wantFixes: 2,
+		},
+		{
+			name:      "duplicates keep the first entry",
+			data:      `[{"date":"2024-01-01","count":1},{"date":"2024-01-01","count":4},{"date":"2024-01-01","count":2}]`,
+			opts:      importValidationOptions{Duplicates: "First"},
+			want:      []ContributionDay{{Date: "2024-01-01", Count: 1}},
+			wantFixes: 2,
+		},
+		{
+			name:      "duplicates summed",
+			data:      `[{"date":"2024-01-01","count":1},{"date":"2024-01-01","count":4},{"date":"2024-01-01","count":2}]`,
+			opts:      importValidationOptions{Duplicates: duplicateSum},
+			want:      []ContributionDay{{Date: "2024-01-01", Count: 7}},
+			wantFixes: 2,
+		},
+		{
+			name:      "duplicates keep the largest",
+			data:      `[{"date":"2024-01-01","count":1},{"date":"2024-01-01","count":4},{"date":"2024-01-01","count":2}]`,
+			opts:      importValidationOptions{Duplicates: duplicateMax},
+			want:      []ContributionDay{{Date: "2024-01-01", Count: 4}},
+			wantFixes: 2,
+		},
+		{
+			name:    "duplicates rejected",
+			data:    `[{"date":"2024-01-01","count":1},{"date":"2024-01-01","count":4}]`,
+			opts:    importValidationOptions{Duplicates: duplicateReject},
+			wantErr: "2024-01-01 already appears as entry 0",
+		},
+		{
+			name:    "unknown duplicate policy",
+			data:    `[]`,
+			opts:    importValidationOptions{Duplicates: "median"},
+			wantErr: "unsupported duplicate policy",
+		},
+		{
+			name:         "array outside the year",
+			data:         `[{"date":"2023-12-31","count":1},{"date":"2024-01-01","count":2}]`,
+			opts:         importValidationOptions{Year: 2024},
+			want:         []ContributionDay{{Date: "2024-01-01", Count: 2}},
+			wantWarnings: 1,
+		},
+		{
+			name: "design window across years ignores the year",
+			data: `{"version":1,"window":{"end":"2024-01-13","weeks":3},"days":[{"date":"2023-12-31","level":4},{"date":"2024-01-02","count":2}]}`,
+			opts: importValidationOptions{Year: 2024},
+			want: []ContributionDay{{Date: "2023-12-31", Count: penLevelCounts[4]}, {Date: "2024-01-02", Count: 2}},
+		},
+		{
+			name:    "design day outside its year",
+			data:    `{"version":1,"year":2024,"days":[{"date":"2023-12-31","count":1}]}`,
+			wantErr: "outside the design's range",
+		},
+		{
+			name: "grid across years ignores the year",
+			data: grid,
+			opts: importValidationOptions{Year: 2024},
+			want: []ContributionDay{{Date: "2023-12-31", Count: penLevelCounts[4]}, {Date: "2024-01-07", Count: penLevelCounts[2]}},
+		},
+		{
+			name:    "neither JSON nor a grid",
+			data:    "date,count\n",
+			wantErr: "line 1, column 1 (offset 0): expected a contributions array",
+		},
+		{
+			name:    "empty",
+			data:    " \n",
+			wantErr: "the file is empty",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got, report, err := validateContributionsFile([]byte(tt.data), tt.opts)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("contributions = %v, want %v", got, tt.want)
+			}
+			if len(report.Warnings) != tt.wantWarnings || len(report.Fixes) != tt.wantFixes {
+				t.Errorf("report has %d warnings and %d fixes, want %d and %d: %+v", len(report.Warnings), len(report.Fixes), tt.wantWarnings, tt.wantFixes, report)
+			}
+			if report.Accepted != len(tt.want) {
+				t.Errorf("accepted %d, want %d", report.Accepted, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestValidateContributionsFileReportsEveryEntry(t *testing.T) {
+	data := `[{"date":"2024-01-01","count":-1},{"date":"2024-01-02","count":1},{"count":2}]`
+	_, _, _, err := validateContributionsFile([]byte(data), importValidationOptions{})
+	var validationErr *ImportValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want an *ImportValidationError", err)
+	}
+	if len(validationErr.Issues) != 2 || validationErr.Issues[0].Index != 0 || validationErr.Issues[1].Index != 2 {
+		t.Errorf("issues = %+v, want entries 0 and 2", validationErr.Issues)
+	}
+}
@@ -0,0 +1,604 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Ways of turning aggregated daily values into commit counts.
+const (
+	csvScaleNone     = "none"     // round the values as they are
+	csvScaleLinear   = "linear"   // proportional to the largest value
+	csvScaleLog      = "log"      // proportional to log(1+value), for long-tailed data
+	csvScaleQuantile = "quantile" // by rank, so every level gets a similar share of days
+)
+
+// Ways of combining several rows for the same day.
+const (
+	csvAggregateSum   = "sum"
+	csvAggregateCount = "count"
+	csvAggregateMax   = "max"
+	csvAggregateMean  = "mean"
+)
+
+const (
+	// defaultCSVMaxCount matches the darkest pen in the editor, so scaled data spans all levels.
+	defaultCSVMaxCount = 9
+	csvDetectionRows   = 200
+	maxCSVWarnings     = 20
+	// maxCSVDayCount caps unscaled days, so a column of amounts doesn't turn into millions of commits.
+	maxCSVDayCount = 1000
+)
+
+// csvGroupedNumbers match numbers with thousands separators, keyed by the decimal separator.
+var csvGroupedNumbers = map[string]*regexp.Regexp{
+	".": regexp.MustCompile(`^[+-]?\d{1,3}(,\d{3})+(\.\d*)?$`),
+	",": regexp.MustCompile(`^[+-]?\d{1,3}(\.\d{3})+(,\d*)?$`),
+}
+
+// csvDateLayouts are tried in order when no date format is given. ISO layouts come first;
+// for slashed dates the month-first form wins unless a day above 12 rules it out.
+var csvDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02T15:04:05Z07:00",
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"2006/01/02",
+	"2006.01.02",
+	"20060102",
+	"01/02/2006",
+	"02/01/2006",
+	"1/2/2006",
+	"2/1/2006",
+	"02.01.2006",
+	"01-02-2006",
+	"02-01-2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+type CSVImportRequest struct {
+	FilePath    string `json:"filePath,omitempty"`    // empty asks with a file dialog
+	Delimiter   string `json:"delimiter,omitempty"`   // "," ";" "\t" or "|"; empty detects it
+	HasHeader   *bool  `json:"hasHeader,omitempty"`   // nil detects it
+	DateColumn  string `json:"dateColumn,omitempty"`  // header name or 1-based column number; empty is the first column
+	ValueColumn string `json:"valueColumn,omitempty"` // header name or 1-based column number; empty counts rows
+	DateFormat  string `json:"dateFormat,omitempty"`  // Go layout or tokens such as "DD/MM/YYYY"; empty detects it
+	Aggregate   string `json:"aggregate,omitempty"`   // sum (default), count, max or mean
+	Scale       string `json:"scale,omitempty"`       // none (default), linear, log or quantile
+	MaxCount    int    `json:"maxCount,omitempty"`    // commits for the largest value when scaling
+	// DecimalSeparator is "." (the default) or ","; the other one may group thousands.
+	DecimalSeparator string `json:"decimalSeparator,omitempty"`
+}
+
+type CSVImportResponse struct {
+	FilePath      string            `json:"filePath"`
+	Columns       []string          `json:"columns"` // header names, or "Column N" without a header
+	DateFormat    string            `json:"dateFormat"`
+	Delimiter     string            `json:"delimiter"`
+	RowsRead      int               `json:"rowsRead"`
+	RowsSkipped   int               `json:"rowsSkipped"`
+	Warnings      []string          `json:"warnings,omitempty"`
+	Contributions []ContributionDay `json:"contributions"`
+}
+
+type ExportContributionsCSVRequest struct {
+	Contributions []ContributionDay `json:"contributions"`
+	Delimiter     string            `json:"delimiter,omitempty"` // empty uses a tab for .tsv files and a comma otherwise
+}
+
+// ImportContributionsCSV reads a CSV or TSV file and aggregates it into daily contributions.
+// The response lists the columns found so the UI can let the user remap them and import again.
+func (a *App) ImportContributionsCSV(req CSVImportRequest) (*CSVImportResponse, error) {
+	filePath := strings.TrimSpace(req.FilePath)
+	if filePath == "" {
+		var err error
+		filePath, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "导入 CSV/TSV 数据",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "表格文件 (*.csv;*.tsv;*.txt)", Pattern: "*.csv;*.tsv;*.txt"},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open file dialog: %w", err)
+		}
+		if filePath == "" {
+			return nil, fmt.Errorf("import cancelled")
+		}
+	}
+
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("read CSV file: %w", err)
+	}
+	if req.Delimiter == "" && strings.EqualFold(filepath.Ext(filePath), ".tsv") {
+		req.Delimiter = "\t"
+	}
+
+	resp, err := parseContributionTable(data, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.FilePath = filePath
+	return resp, nil
+}
+
+// ExportContributionsCSV writes the contributions as "date,count" rows, the layout
+// ImportContributionsCSV reads back unchanged with its defaults.
+func (a *App) ExportContributionsCSV(req ExportContributionsCSVRequest) (*ExportContributionsResponse, error) {
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出 CSV 数据",
+		DefaultFilename: "contributions.csv",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "CSV 文件 (*.csv)", Pattern: "*.csv"},
+			{DisplayName: "TSV 文件 (*.tsv)", Pattern: "*.tsv"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+
+	delimiter := req.Delimiter
+	if delimiter == "" && strings.EqualFold(filepath.Ext(filePath), ".tsv") {
+		delimiter = "\t"
+	}
+	data, err := encodeContributionsCSV(req.Contributions, delimiter)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filePath, data, 0o644); err != nil {
+		return nil, fmt.Errorf("write contributions to file: %w", err)
+	}
+	return &ExportContributionsResponse{FilePath: filePath}, nil
+}
+
+func encodeContributionsCSV(contributions []ContributionDay, delimiter string) ([]byte, error) {
+	comma, err := csvDelimiter(delimiter)
+	if err != nil {
+		return nil, err
+	}
+	sorted := append([]ContributionDay(nil), contributions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	writer.Comma = comma
+	_ = writer.Write([]string{"date", "count"})
+	for _, day := range sorted {
+		_ = writer.Write([]string{day.Date, strconv.Itoa(day.Count)})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("encode CSV: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseContributionTable does the work of ImportContributionsCSV on file contents.
+func parseContributionTable(data []byte, req CSVImportRequest) (*CSVImportResponse, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	if req.Delimiter == "" {
+		req.Delimiter = detectCSVDelimiter(data)
+	}
+	comma, err := csvDelimiter(req.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+	aggregate := strings.ToLower(strings.TrimSpace(req.Aggregate))
+	if aggregate == "" {
+		aggregate = csvAggregateSum
+	}
+	switch aggregate {
+	case csvAggregateSum, csvAggregateCount, csvAggregateMax, csvAggregateMean:
+	default:
+		return nil, fmt.Errorf("unknown aggregation %q (use sum, count, max or mean)", req.Aggregate)
+	}
+	decimal := req.DecimalSeparator
+	switch decimal {
+	case "":
+		decimal = "."
+	case ".", ",":
+	default:
+		return nil, fmt.Errorf("unsupported decimal separator %q (use . or ,)", req.DecimalSeparator)
+	}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+	var rows [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parse CSV: %w", err)
+		}
+		if len(record) == 1 && strings.TrimSpace(record[0]) == "" {
+			continue
+		}
+		rows = append(rows, record)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("the file contains no rows")
+	}
+
+	var hasHeader bool
+	if req.HasHeader != nil {
+		hasHeader = *req.HasHeader
+	} else {
+		hasHeader = looksLikeHeader(rows, decimal)
+	}
+	var columns []string
+	if hasHeader {
+		for _, name := range rows[0] {
+			columns = append(columns, strings.TrimSpace(name))
+		}
+		rows = rows[1:]
+	} else {
+		for i := range rows[0] {
+			columns = append(columns, fmt.Sprintf("Column %d", i+1))
+		}
+	}
+
+	dateIndex := 0
+	if req.DateColumn != "" {
+		if dateIndex, err = resolveCSVColumn(columns, req.DateColumn); err != nil {
+			return nil, err
+		}
+	}
+	valueIndex := -1
+	if req.ValueColumn != "" {
+		if valueIndex, err = resolveCSVColumn(columns, req.ValueColumn); err != nil {
+			return nil, err
+		}
+	}
+	if valueIndex < 0 {
+		aggregate = csvAggregateCount
+	}
+
+	layout := goDateLayout(req.DateFormat)
+	if layout == "" {
+		if layout, err = detectDateLayout(rows, dateIndex); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &CSVImportResponse{
+		Columns:    columns,
+		DateFormat: layout,
+		Delimiter:  string(comma),
+		RowsRead:   len(rows),
+	}
+	note := func(format string, args ...interface{}) {
+		if len(resp.Warnings) < maxCSVWarnings {
+			resp.Warnings = append(resp.Warnings, fmt.Sprintf(format, args...))
+		}
+	}
+	warn := func(format string, args ...interface{}) {
+		resp.RowsSkipped++
+		note(format, args...)
+	}
+	notedGrouping := false
+
+	type dayTotal struct {
+		sum, max float64
+		rows     int
+	}
+	days := map[string]*dayTotal{}
+	firstRow := 1
+	if hasHeader {
+		firstRow = 2
+	}
+	for i, row := range rows {
+		line := i + firstRow
+		if dateIndex >= len(row) || strings.TrimSpace(row[dateIndex]) == "" {
+			warn("row %d: no date", line)
+			continue
+		}
+		date, err := time.Parse(layout, strings.TrimSpace(row[dateIndex]))
+		if err != nil {
+			warn("row %d: %q does not match the date format %s", line, row[dateIndex], layout)
+			continue
+		}
+		value := 1.0
+		if valueIndex >= 0 {
+			if valueIndex >= len(row) {
+				warn("row %d: no value", line)
+				continue
+			}
+			value, err = parseCSVNumber(row[valueIndex], decimal)
+			if err != nil {
+				warn("row %d: %v", line, err)
+				continue
+			}
+			if value < 0 {
+				warn("row %d: negative value %v", line, value)
+				continue
+			}
+			// Without a separator given, "1,234" could be either convention; say which was used.
+			if req.DecimalSeparator == "" && !notedGrouping && strings.Contains(row[valueIndex], ",") {
+				notedGrouping = true
+				note("row %d: read %q as %v; set the decimal separator to \",\" if commas mark decimals", line, strings.TrimSpace(row[valueIndex]), value)
+			}
+		}
+
+		key := date.Format("2006-01-02")
+		total := days[key]
+		if total == nil {
+			total = &dayTotal{}
+			days[key] = total
+		}
+		// Huge values can't add up to infinity, which no scale can place.
+		total.sum = math.Min(total.sum+value, math.MaxFloat64)
+		total.rows++
+		total.max = math.Max(total.max, value)
+	}
+
+	dates := make([]string, 0, len(days))
+	values := make([]float64, 0, len(days))
+	for date := range days {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		total := days[date]
+		switch aggregate {
+		case csvAggregateCount:
+			values = append(values, float64(total.rows))
+		case csvAggregateMax:
+			values = append(values, total.max)
+		case csvAggregateMean:
+			values = append(values, total.sum/float64(total.rows))
+		default:
+			values = append(values, total.sum)
+		}
+	}
+
+	counts, err := scaleContributionValues(values, req.Scale, req.MaxCount)
+	if err != nil {
+		return nil, err
+	}
+	capped := 0
+	for i, value := range values {
+		if counts[i] == maxCSVDayCount && value > maxCSVDayCount {
+			capped++
+		}
+	}
+	if capped > 0 {
+		note("%d day(s) above %d were capped at %d commits; choose a scale to keep their proportions", capped, maxCSVDayCount, maxCSVDayCount)
+	}
+	resp.Contributions = make([]ContributionDay, 0, len(dates))
+	for i, date := range dates {
+		resp.Contributions = append(resp.Contributions, ContributionDay{Date: date, Count: counts[i]})
+	}
+	return resp, nil
+}
+
+// scaleContributionValues maps non-negative daily values to commit counts. Days with a
+// value above zero always get at least one commit; no day gets more than maxCSVDayCount.
+func scaleContributionValues(values []float64, scale string, maxCount int) ([]int, error) {
+	if maxCount <= 0 {
+		maxCount = defaultCSVMaxCount
+	}
+	if maxCount > maxCSVDayCount {
+		return nil, fmt.Errorf("maximum commits per day must be at most %d, got %d", maxCSVDayCount, maxCount)
+	}
+	largest := 0.0
+	for _, value := range values {
+		largest = math.Max(largest, value)
+	}
+
+	counts := make([]int, len(values))
+	scaled := func(fraction float64) int {
+		return int(math.Max(1, math.Round(fraction*float64(maxCount))))
+	}
+	switch strings.ToLower(strings.TrimSpace(scale)) {
+	case "", csvScaleNone:
+		for i, value := range values {
+			counts[i] = int(math.Min(math.Round(value), maxCSVDayCount))
+			if counts[i] == 0 && value > 0 {
+				counts[i] = 1
+			}
+		}
+	case csvScaleLinear:
+		for i, value := range values {
+			if value > 0 {
+				counts[i] = scaled(value / largest)
+			}
+		}
+	case csvScaleLog:
+		for i, value := range values {
+			if value > 0 {
+				counts[i] = scaled(math.Log1p(value) / math.Log1p(largest))
+			}
+		}
+	case csvScaleQuantile:
+		var positive []float64
+		for _, value := range values {
+			if value > 0 {
+				positive = append(positive, value)
+			}
+		}
+		sort.Float64s(positive)
+		for i, value := range values {
+			if value <= 0 {
+				continue
+			}
+			// Equal values share the rank of the last of them.
+			rank := sort.Search(len(positive), func(j int) bool { return positive[j] > value })
+			counts[i] = scaled(float64(rank) / float64(len(positive)))
+		}
+	default:
+		return nil, fmt.Errorf("unknown scale %q (use none, linear, log or quantile)", scale)
+	}
+	return counts, nil
+}
+
+func csvDelimiter(delimiter string) (rune, error) {
+	switch delimiter {
+	case "", ",":
+		return ',', nil
+	case "\t", `\t`, "tab":
+		return '\t', nil
+	case ";":
+		return ';', nil
+	case "|":
+		return '|', nil
+	default:
+		return 0, fmt.Errorf("unsupported delimiter %q", delimiter)
+	}
+}
+
+// detectCSVDelimiter picks the candidate that splits the first lines most consistently.
+func detectCSVDelimiter(data []byte) string {
+	lines := strings.Split(string(data), "\n")
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+	best, bestScore := ",", 0
+	for _, candidate := range []string{",", "\t", ";", "|"} {
+		score := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			n := strings.Count(line, candidate)
+			if n == 0 {
+				score = 0
+				break
+			}
+			if score < 0 || n < score {
+				score = n
+			}
+		}
+		if score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	return best
+}
+
+// looksLikeHeader treats the first row as a header when none of its cells is a date or number.
+func looksLikeHeader(rows [][]string, decimal string) bool {
+	for _, cell := range rows[0] {
+		cell = strings.TrimSpace(cell)
+		if _, err := parseCSVNumber(cell, decimal); err == nil {
+			return false
+		}
+		for _, layout := range csvDateLayouts {
+			if _, err := time.Parse(layout, cell); err == nil {
+				return false
+			}
+		}
+	}
+	return len(rows) > 1
+}
+
+func resolveCSVColumn(columns []string, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	for i, column := range columns {
+		if strings.EqualFold(column, name) {
+			return i, nil
+		}
+	}
+	if n, err := strconv.Atoi(name); err == nil && n >= 1 && n <= len(columns) {
+		return n - 1, nil
+	}
+	return 0, fmt.Errorf("column %q not found (available: %s)", name, strings.Join(columns, ", "))
+}
+
+// detectDateLayout returns the layout that parses the most sampled dates, preferring the
+// earlier layout on a tie. Stray rows such as totals don't throw detection off.
+func detectDateLayout(rows [][]string, column int) (string, error) {
+	var samples []string
+	for _, row := range rows {
+		if column < len(row) && strings.TrimSpace(row[column]) != "" {
+			samples = append(samples, strings.TrimSpace(row[column]))
+			if len(samples) == csvDetectionRows {
+				break
+			}
+		}
+	}
+	if len(samples) == 0 {
+		return "", fmt.Errorf("the date column is empty")
+	}
+
+	best, bestMatches := "", 0
+	for _, layout := range csvDateLayouts {
+		matches := 0
+		for _, sample := range samples {
+			if _, err := time.Parse(layout, sample); err == nil {
+				matches++
+			}
+		}
+		if matches > bestMatches {
+			best, bestMatches = layout, matches
+		}
+	}
+	// Most dates must agree; otherwise the column probably isn't a date column.
+	if bestMatches*2 <= len(samples) {
+		return "", fmt.Errorf("could not detect the date format of %q; set the date format explicitly", samples[0])
+	}
+	return best, nil
+}
+
+// goDateLayout accepts a Go layout or a pattern written with YYYY, MM, DD, HH, mm and ss.
+func goDateLayout(format string) string {
+	format = strings.TrimSpace(format)
+	if format == "" || strings.Contains(format, "2006") {
+		return format
+	}
+	return strings.NewReplacer(
+		"YYYY", "2006", "yyyy", "2006",
+		"MM", "01", "DD", "02", "dd", "02",
+		"HH", "15", "mm", "04", "ss", "05",
+	).Replace(format)
+}
+
+// parseCSVNumber reads a finite number written with the given decimal separator. The other
+// of "." and "," is accepted only as a thousands separator between groups of three digits.
+func parseCSVNumber(cell, decimal string) (float64, error) {
+	original := strings.TrimSpace(cell)
+	cell = strings.ReplaceAll(original, "_", "")
+	notNumber := fmt.Errorf("%q is not a number with %q as the decimal separator", original, decimal)
+	thousands := ","
+	if decimal == "," {
+		thousands = "."
+	}
+	if strings.Contains(cell, thousands) {
+		if !csvGroupedNumbers[decimal].MatchString(cell) {
+			return 0, notNumber
+		}
+		cell = strings.ReplaceAll(cell, thousands, "")
+	}
+	cell = strings.Replace(cell, decimal, ".", 1)
+	value, err := strconv.ParseFloat(cell, 64)
+	if err != nil {
+		return 0, notNumber
+	}
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%q is not a finite number", original)
+	}
+	return value, nil
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestScaleContributionValuesRejectsLargeMaximum(t *testing.T) {
+	if _, err := scaleContributionValues([]float64{1, 2}, csvScaleLinear, maxCSVDayCount); err != nil {
+		t.Fatalf("maximum of %d rejected: %v", maxCSVDayCount, err)
+	}
+	if _, err := scaleContributionValues([]float64{1, 2}, csvScaleLinear, maxCSVDayCount+1); err == nil {
+		t.Fatalf("maximum of %d accepted", maxCSVDayCount+1)
+	}
+}
+
+func TestScaleContributionValues(t *testing.T) {
+	tests := []struct {
+		name     string
+		values   []float64
+		scale    string
+		maxCount int
+		want     []int
+	}{
+		{
+			name:   "none rounds and keeps small values visible",
+			values: []float64{0, 0.2, 1.5, 7},
+			scale:  csvScaleNone,
+			want:   []int{0, 1, 2, 7},
+		},
+		{
+			name:   "none caps large values",
+			values: []float64{12, 5000, 1e300},
+			want:   []int{12, maxCSVDayCount, maxCSVDayCount},
+		},
+		{
+			name:     "linear is proportional to the largest value",
+			values:   []float64{0, 1, 50, 100},
+			scale:    csvScaleLinear,
+			maxCount: 10,
+			want:     []int{0, 1, 5, 10},
+		},
+		{
+			name:   "linear uses the default maximum",
+			values: []float64{3, 6},
+			scale:  "Linear",
+			want:   []int{5, defaultCSVMaxCount},
+		},
+		{
+			name:     "log compresses a long tail",
+			values:   []float64{0, 9, 99, 999},
+			scale:    csvScaleLog,
+			maxCount: 9,
+			want:     []int{0, 3, 6, 9},
+		},
+		{
+			name:     "quantile ranks values and ties share a rank",
+			values:   []float64{10, 0, 1000, 10, 20},
+			scale:    csvScaleQuantile,
+			maxCount: 4,
+			want:     []int{2, 0, 4, 2, 3},
+		},
+		{
+			name:     "all zero",
+			values:   []float64{0, 0},
+			scale:    csvScaleLinear,
+			maxCount: 4,
+			want:     []int{0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := scaleContributionValues(tt.values, tt.scale, tt.maxCount)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("scaleContributionValues(%v, %q, %d) = %v, want %v", tt.values, tt.scale, tt.maxCount, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := scaleContributionValues([]float64{1}, "cubic", 0); err == nil {
+		t.Fatal("an unknown scale was accepted")
+	}
+}
+
+func TestParseCSVNumber(t *testing.T) {
+	tests := []struct {
+		cell    string
+		decimal string
+		want    float64
+		wantErr bool
+	}{
+		{cell: "42", decimal: ".", want: 42},
+		{cell: " 1.5 ", decimal: ".", want: 1.5},
+		{cell: "1,234", decimal: ".", want: 1234},
+		{cell: "1,234,567.5", decimal: ".", want: 1234567.5},
+		{cell: "1_000", decimal: ".", want: 1000},
+		{cell: "1,5", decimal: ".", wantErr: true},
+		{cell: "12,34.5", decimal: ".", wantErr: true},
+		{cell: "1,234", decimal: ",", want: 1.234},
+		{cell: "1.234,5", decimal: ",", want: 1234.5},
+		{cell: "1.5", decimal: ",", wantErr: true},
+		{cell: "NaN", decimal: ".", wantErr: true},
+		{cell: "Inf", decimal: ".", wantErr: true},
+		{cell: "-infinity", decimal: ".", wantErr: true},
+		{cell: "1e400", decimal: ".", wantErr: true},
+		{cell: "commits", decimal: ".", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseCSVNumber(tt.cell, tt.decimal)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("parseCSVNumber(%q, %q) = %v, want an error", tt.cell, tt.decimal, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("parseCSVNumber(%q, %q) = %v, %v, want %v", tt.cell, tt.decimal, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseContributionTableSkipsNonFiniteValues(t *testing.T) {
+	data := "date,value\n2024-01-01,3\n2024-01-02,NaN\n2024-01-03,inf\n2024-01-04,\"1,5\"\n"
+	resp, err := parseContributionTable([]byte(data), CSVImportRequest{ValueColumn: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ContributionDay{{Date: "2024-01-01", Count: 3}}
+	if !reflect.DeepEqual(resp.Contributions, want) {
+		t.Fatalf("contributions = %v, want %v", resp.Contributions, want)
+	}
+	if resp.RowsSkipped != 3 {
+		t.Fatalf("skipped %d rows, want 3: %v", resp.RowsSkipped, resp.Warnings)
+	}
+}
+
+func TestParseContributionTableDecimalSeparator(t *testing.T) {
+	data := "date;value\n2024-01-01;1,234\n2024-01-02;2\n"
+
+	resp, err := parseContributionTable([]byte(data), CSVImportRequest{ValueColumn: "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Contributions[0].Count; got != maxCSVDayCount {
+		t.Fatalf("1,234 imported as %d, want the capped %d", got, maxCSVDayCount)
+	}
+	if len(resp.Warnings) != 2 || !strings.Contains(resp.Warnings[0], "decimal separator") || !strings.Contains(resp.Warnings[1], "capped") {
+		t.Fatalf("warnings = %q, want a note about the separator and the cap", resp.Warnings)
+	}
+
+	resp, err = parseContributionTable([]byte(data), CSVImportRequest{ValueColumn: "value", DecimalSeparator: ","})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ContributionDay{{Date: "2024-01-01", Count: 1}, {Date: "2024-01-02", Count: 2}}
+	if !reflect.DeepEqual(resp.Contributions, want) || len(resp.Warnings) != 0 {
+		t.Fatalf("contributions = %v, warnings = %q, want %v without warnings", resp.Contributions, resp.Warnings, want)
+	}
+
+	if _, err := parseContributionTable([]byte(data), CSVImportRequest{DecimalSeparator: "'"}); err == nil {
+		t.Fatal("an unsupported decimal separator was accepted")
+	}
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	designFileVersion   = 1
+	designFileExtension = ".greenwall"
+	designSchemaURL     = "https://raw.githubusercontent.com/zmrlft/GreenWall/main/docs/greenwall.schema.json"
+	maxDesignWindowWeek = 53
+)
+
+// DesignDocument is a .greenwall file: a design plus what is needed to generate it again.
+// docs/greenwall.schema.json describes the format and must be kept in step with it.
+type DesignDocument struct {
+	Schema   string             `json:"$schema,omitempty"`
+	Version  int                `json:"version"`
+	Title    string             `json:"title,omitempty"`
+	Author   string             `json:"author,omitempty"`
+	Year     int                `json:"year,omitempty"`   // the calendar year drawn; exclusive with Window
+	Window   *DesignWindow      `json:"window,omitempty"` // a rolling window like the profile view
+	Days     []DesignDay        `json:"days"`
+	Identity *DesignIdentity    `json:"identity,omitempty"`
+	Timezone string             `json:"timezone,omitempty"` // IANA zone the commits are dated in; empty is UTC
+	Remote   *RemoteRepoOptions `json:"remote,omitempty"`
+}
+
+// DesignWindow is the Weeks weeks up to and including the week of End, the way GitHub
+// shows the past year on a profile.
+type DesignWindow struct {
+	End   string `json:"end"` // YYYY-MM-DD
+	Weeks int    `json:"weeks"`
+}
+
+// DesignDay sets one day either by commit count or by shade, which maps to the pen counts.
+type DesignDay struct {
+	Date  string `json:"date"`
+	Count int    `json:"count,omitempty"`
+	Level int    `json:"level,omitempty"` // 1–4
+}
+
+// DesignIdentity is the author of the generated commits.
+type DesignIdentity struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+}
+
+type ExportDesignRequest struct {
+	Title         string             `json:"title,omitempty"`
+	Author        string             `json:"author,omitempty"`
+	Year          int                `json:"year,omitempty"`
+	Window        *DesignWindow      `json:"window,omitempty"`
+	Contributions []ContributionDay  `json:"contributions"`
+	StoreLevels   bool               `json:"storeLevels,omitempty"` // save shades instead of exact counts
+	Identity      *DesignIdentity    `json:"identity,omitempty"`
+	Timezone      string             `json:"timezone,omitempty"`
+	Remote        *RemoteRepoOptions `json:"remote,omitempty"`
+}
+
+// ExportDesign saves the design and its settings as a .greenwall file.
+func (a *App) ExportDesign(req ExportDesignRequest) (*ExportContributionsResponse, error) {
+	doc := newDesignDocument(req)
+	if _, err := doc.contributions(); err != nil {
+		return nil, err
+	}
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal design: %w", err)
+	}
+
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出设计",
+		DefaultFilename: "design" + designFileExtension,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "GreenWall 设计 (*.greenwall)", Pattern: "*" + designFileExtension},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+
+	if err := os.WriteFile(filePath, append(data, '\n'), 0o644); err != nil {
+		return nil, fmt.Errorf("write design to file: %w", err)
+	}
+	return &ExportContributionsResponse{FilePath: filePath}, nil
+}
+
+func newDesignDocument(req ExportDesignRequest) *DesignDocument {
+	doc := &DesignDocument{
+		Schema:   designSchemaURL,
+		Version:  designFileVersion,
+		Title:    strings.TrimSpace(req.Title),
+		Author:   strings.TrimSpace(req.Author),
+		Year:     req.Year,
+		Window:   req.Window,
+		Days:     []DesignDay{},
+		Identity: req.Identity,
+		Timezone: strings.TrimSpace(req.Timezone),
+		Remote:   req.Remote,
+	}
+	sorted := append([]ContributionDay(nil), req.Contributions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Date < sorted[j].Date })
+	for _, day := range sorted {
+		if day.Count == 0 {
+			continue
+		}
+		if req.StoreLevels {
+			doc.Days = append(doc.Days, DesignDay{Date: day.Date, Level: contributionLevel(day.Count)})
+		} else {
+			doc.Days = append(doc.Days, DesignDay{Date: day.Date, Count: day.Count})
+		}
+	}
+	return doc
+}
+
+// decodeDesign reads a .greenwall document, or a bare contributions array as written by
+// earlier versions or a text grid, which come back without a document. Duplicate days keep
+// the last entry.
+func decodeDesign(data []byte) (*DesignDocument, []ContributionDay, error) {
+	doc, contributions, _, err := validateContributionsFile(data, importValidationOptions{})
+	return doc, contributions, err
+}
+
+// contributions validates the document and returns its days as commit counts.
+func (doc *DesignDocument) contributions() ([]ContributionDay, error) {
+	start, end, err := doc.checkHeader()
+	if err != nil {
+		return nil, err
+	}
+	contributions := make([]ContributionDay, 0, len(doc.Days))
+	for _, day := range doc.Days {
+		count, err := designDayCount(day, start, end)
+		if err != nil {
+			return nil, err
+		}
+		contributions = append(contributions, ContributionDay{Date: day.Date, Count: count})
+	}
+	return contributions, nil
+}
+
+// checkHeader validates everything but the days and returns the range the days must fall in.
+func (doc *DesignDocument) checkHeader() (time.Time, time.Time, error) {
+	switch {
+	case doc.Version == 0:
+		return time.Time{}, time.Time{}, fmt.Errorf("not a GreenWall design: the version is missing")
+	case doc.Version > designFileVersion:
+		return time.Time{}, time.Time{}, fmt.Errorf("the design was saved by a newer GreenWall (format version %d); update to open it", doc.Version)
+	}
+	if doc.Timezone != "" {
+		if _, err := time.LoadLocation(doc.Timezone); err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("unknown time zone %q", doc.Timezone)
+		}
+	}
+	return doc.span()
+}
+
+// designDayCount validates a day against the document's range and returns its commit count.
+func designDayCount(day DesignDay, start, end time.Time) (int, error) {
+	date, err := time.Parse("2006-01-02", day.Date)
+	if err != nil {
+		return 0, fmt.Errorf("invalid date %q: use YYYY-MM-DD", day.Date)
+	}
+	if !start.IsZero() && (date.Before(start) || date.After(end)) {
+		return 0, fmt.Errorf("%s is outside the design's range %s to %s", day.Date, start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+	switch {
+	case day.Count != 0 && day.Level != 0:
+		return 0, fmt.Errorf("%s sets both a count and a level", day.Date)
+	case day.Count < 0:
+		return 0, fmt.Errorf("invalid contribution count for %s: %d", day.Date, day.Count)
+	case day.Level < 0 || day.Level >= len(penLevelCounts):
+		return 0, fmt.Errorf("invalid level for %s: %d (use 1–4)", day.Date, day.Level)
+	case day.Level > 0:
+		return penLevelCounts[day.Level], nil
+	default:
+		return day.Count, nil
+	}
+}
+
+// span returns the first and last day the document covers, or zero times when it names
+// neither a year nor a window.
+func (doc *DesignDocument) span() (time.Time, time.Time, error) {
+	switch {
+	case doc.Year != 0 && doc.Window != nil:
+		return time.Time{}, time.Time{}, fmt.Errorf("a design sets either a year or a window, not both")
+	case doc.Year != 0:
+		if doc.Year < 1970 || doc.Year > 9999 {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid year %d", doc.Year)
+		}
+		return time.Date(doc.Year, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(doc.Year, time.December, 31, 0, 0, 0, 0, time.UTC), nil
+	case doc.Window != nil:
+		end, err := time.Parse("2006-01-02", doc.Window.End)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid window end %q: use YYYY-MM-DD", doc.Window.End)
+		}
+		if doc.Window.Weeks < 1 || doc.Window.Weeks > maxDesignWindowWeek {
+			return time.Time{}, time.Time{}, fmt.Errorf("a window spans 1 to %d weeks", maxDesignWindowWeek)
+		}
+		weekStart := end.AddDate(0, 0, -int(end.Weekday()))
+		return weekStart.AddDate(0, 0, -7*(doc.Window.Weeks-1)), end, nil
+	default:
+		return time.Time{}, time.Time{}, nil
+	}
+}
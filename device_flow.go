@@ -0,0 +1,290 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	defaultGithubWebBase   = "https://github.com"
+	githubDeviceLoginEvent = "github:device-login"
+	githubDeviceScopes     = "repo delete_repo read:user user:email"
+	deviceGrantType        = "urn:ietf:params:oauth:grant-type:device_code"
+
+	// Device codes last 15 minutes unless GitHub says otherwise.
+	defaultDeviceCodeLifetime = 900
+	// maxDevicePollBackoff caps the wait between retries after network or server errors.
+	maxDevicePollBackoff = 60
+)
+
+// devicePollUnit is the unit of the intervals GitHub gives in seconds; tests shorten it.
+var devicePollUnit = time.Second
+
+// githubOAuthClientID identifies the GreenWall OAuth app used for the device flow.
+// .github/workflows/build.yml sets it with -ldflags "-X main.githubOAuthClientID=..." from
+// the GREENWALL_GITHUB_CLIENT_ID repository variable; the environment variable of the same
+// name overrides it at run time.
+var githubOAuthClientID = ""
+
+// Device login states carried by githubDeviceLoginEvent.
+const (
+	deviceLoginPending    = "pending"
+	deviceLoginAuthorized = "authorized"
+	deviceLoginExpired    = "expired"
+	deviceLoginDenied     = "denied"
+	deviceLoginCancelled  = "cancelled"
+	deviceLoginFailed     = "error"
+)
+
+type DeviceLoginRequest struct {
+	Host       string `json:"host,omitempty"` // GitHub Enterprise Server host; empty means github.com
+	Remember   bool   `json:"remember"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+type DeviceLoginStart struct {
+	UserCode        string `json:"userCode"`
+	VerificationURI string `json:"verificationUri"`
+	ExpiresIn       int    `json:"expiresIn"` // seconds
+	Interval        int    `json:"interval"`  // seconds between polls
+}
+
+type DeviceLoginEvent struct {
+	Status   string              `json:"status"`
+	Message  string              `json:"message,omitempty"`
+	Response *GithubAuthResponse `json:"response,omitempty"`
+}
+
+type deviceCodeResponse struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationURI string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+}
+
+type deviceTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+	Interval         int    `json:"interval"`
+}
+
+// StartGithubDeviceLogin requests a device code, opens the verification page and polls
+// for the token in the background. Progress is reported through githubDeviceLoginEvent.
+func (a *App) StartGithubDeviceLogin(req DeviceLoginRequest) (*DeviceLoginStart, error) {
+	clientID := githubOAuthClientID
+	if env := strings.TrimSpace(os.Getenv("GREENWALL_GITHUB_CLIENT_ID")); env != "" {
+		clientID = env
+	}
+	if clientID == "" {
+		return nil, fmt.Errorf("device login is not configured: no GitHub OAuth client ID")
+	}
+
+	host, err := normaliseGithubHost(req.Host)
+	if err != nil {
+		return nil, err
+	}
+	req.Host = host
+
+	code, err := a.requestDeviceCode(host, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.mu.Lock()
+	if a.cancelDeviceLogin != nil {
+		a.cancelDeviceLogin()
+	}
+	a.cancelDeviceLogin = cancel
+	a.deviceLoginID++
+	id := a.deviceLoginID
+	a.mu.Unlock()
+
+	go func() {
+		event := a.pollDeviceLogin(ctx, clientID, code, req)
+		a.finishDeviceLogin(id, cancel)
+		a.emitDeviceLogin(event)
+	}()
+
+	if a.ctx != nil {
+		runtime.BrowserOpenURL(a.ctx, code.VerificationURI)
+	}
+
+	return &DeviceLoginStart{
+		UserCode:        code.UserCode,
+		VerificationURI: code.VerificationURI,
+		ExpiresIn:       code.ExpiresIn,
+		Interval:        code.Interval,
+	}, nil
+}
+
+// CancelGithubDeviceLogin stops polling for a device login that is in progress.
+func (a *App) CancelGithubDeviceLogin() {
+	a.mu.Lock()
+	cancel := a.cancelDeviceLogin
+	a.cancelDeviceLogin = nil
+	a.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+	}
+}
+
+// finishDeviceLogin forgets the cancel func of a poll that has ended, unless a newer
+// login has replaced it already.
+func (a *App) finishDeviceLogin(id uint64, cancel context.CancelFunc) {
+	a.mu.Lock()
+	if a.deviceLoginID == id {
+		a.cancelDeviceLogin = nil
+	}
+	a.mu.Unlock()
+	cancel()
+}
+
+func (a *App) requestDeviceCode(host, clientID string) (*deviceCodeResponse, error) {
+	form := url.Values{"client_id": {clientID}, "scope": {githubDeviceScopes}}
+	var code deviceCodeResponse
+	if err := a.postDeviceForm(host, "/login/device/code", form, &code); err != nil {
+		return nil, fmt.Errorf("request device code failed: %w", err)
+	}
+	if code.DeviceCode == "" || code.UserCode == "" {
+		return nil, fmt.Errorf("GitHub did not return a device code")
+	}
+	if code.Interval <= 0 {
+		code.Interval = 5
+	}
+	if code.ExpiresIn <= 0 {
+		code.ExpiresIn = defaultDeviceCodeLifetime
+	}
+	return &code, nil
+}
+
+// pollDeviceLogin polls until the user authorizes the device code, denies it or it expires,
+// and returns the event describing the outcome. Network errors and 5xx answers are retried
+// with a growing delay until the code expires.
+func (a *App) pollDeviceLogin(ctx context.Context, clientID string, code *deviceCodeResponse, req DeviceLoginRequest) DeviceLoginEvent {
+	interval := time.Duration(code.Interval) * devicePollUnit
+	deadline := time.Now().Add(time.Duration(code.ExpiresIn) * devicePollUnit)
+	wait := interval
+
+	a.emitDeviceLogin(DeviceLoginEvent{Status: deviceLoginPending})
+	for {
+		select {
+		case <-ctx.Done():
+			return DeviceLoginEvent{Status: deviceLoginCancelled}
+		case <-time.After(wait):
+		}
+		wait = interval
+
+		if time.Now().After(deadline) {
+			return DeviceLoginEvent{Status: deviceLoginExpired, Message: "the device code expired before it was authorized"}
+		}
+
+		form := url.Values{
+			"client_id":   {clientID},
+			"device_code": {code.DeviceCode},
+			"grant_type":  {deviceGrantType},
+		}
+		var token deviceTokenResponse
+		if err := a.postDeviceForm(req.Host, "/login/oauth/access_token", form, &token); err != nil {
+			if !retryableDeviceError(err) {
+				return DeviceLoginEvent{Status: deviceLoginFailed, Message: err.Error()}
+			}
+			wait = nextDeviceBackoff(wait)
+			if a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "device login poll failed, retrying in %s: %v", wait, err)
+			}
+			continue
+		}
+
+		switch token.Error {
+		case "":
+			if token.AccessToken == "" {
+				return DeviceLoginEvent{Status: deviceLoginFailed, Message: "GitHub did not return an access token"}
+			}
+			if ctx.Err() != nil {
+				return DeviceLoginEvent{Status: deviceLoginCancelled}
+			}
+			resp, err := a.signInWithToken(req.Host, token.AccessToken, req.Remember, req.Passphrase)
+			if err != nil {
+				return DeviceLoginEvent{Status: deviceLoginFailed, Message: err.Error()}
+			}
+			return DeviceLoginEvent{Status: deviceLoginAuthorized, Response: resp}
+		case "authorization_pending":
+		case "slow_down":
+			// GitHub adds five seconds per slow_down and reports the new interval.
+			if token.Interval > 0 {
+				interval = time.Duration(token.Interval) * devicePollUnit
+			} else {
+				interval += 5 * devicePollUnit
+			}
+			wait = interval
+		case "expired_token":
+			return DeviceLoginEvent{Status: deviceLoginExpired, Message: token.ErrorDescription}
+		case "access_denied":
+			return DeviceLoginEvent{Status: deviceLoginDenied, Message: token.ErrorDescription}
+		default:
+			return DeviceLoginEvent{Status: deviceLoginFailed, Message: fmt.Sprintf("%s: %s", token.Error, token.ErrorDescription)}
+		}
+	}
+}
+
+// retryableDeviceError reports failures that say nothing about the device code: GitHub
+// could not be reached, or answered with a server error.
+func retryableDeviceError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, errGithubUnavailable)
+}
+
+// nextDeviceBackoff doubles the wait after a failed poll, up to maxDevicePollBackoff.
+func nextDeviceBackoff(wait time.Duration) time.Duration {
+	wait *= 2
+	if limit := maxDevicePollBackoff * devicePollUnit; wait > limit {
+		wait = limit
+	}
+	return wait
+}
+
+func (a *App) postDeviceForm(host, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequest(http.MethodPost, a.webBaseForHost(host)+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		err := fmt.Errorf("GitHub returned error for %s (%d): %s", path, resp.StatusCode, strings.TrimSpace(string(body)))
+		if resp.StatusCode >= 500 {
+			err = fmt.Errorf("%w: %v", errGithubUnavailable, err)
+		}
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+func (a *App) emitDeviceLogin(event DeviceLoginEvent) {
+	if a.ctx == nil {
+		return
+	}
+	runtime.EventsEmit(a.ctx, githubDeviceLoginEvent, event)
+}
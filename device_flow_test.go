@@ -0,0 +1,213 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type deviceTokenReply func(w http.ResponseWriter)
+
+func deviceJSON(body string) deviceTokenReply {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}
+}
+
+func deviceStatus(code int) deviceTokenReply {
+	return func(w http.ResponseWriter) { w.WriteHeader(code) }
+}
+
+// deviceDrop closes the connection without answering, as a network failure would.
+func deviceDrop(w http.ResponseWriter) {
+	conn, _, err := w.(http.Hijacker).Hijack()
+	if err == nil {
+		conn.Close()
+	}
+}
+
+// newDeviceFlowServer answers the access token endpoint with replies in turn, repeating
+// the last one, and serves /user for the sign-in that follows.
+func newDeviceFlowServer(t *testing.T, replies []deviceTokenReply) (*App, *int32) {
+	var polls int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.Form.Get("device_code") != "device-code" || r.Form.Get("grant_type") != deviceGrantType {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+		n := int(atomic.AddInt32(&polls, 1)) - 1
+		if n >= len(replies) {
+			n = len(replies) - 1
+		}
+		replies[n](w)
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer gho_device" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo, delete_repo")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "login": "octocat", "email": "octocat@example.com"})
+	})
+	app, _ := newTestApp(t, mux)
+	return app, &polls
+}
+
+func TestPollDeviceLogin(t *testing.T) {
+	previous := devicePollUnit
+	devicePollUnit = time.Millisecond
+	t.Cleanup(func() { devicePollUnit = previous })
+
+	authorized := deviceJSON(`{"access_token":"gho_device","token_type":"bearer"}`)
+	pending := deviceJSON(`{"error":"authorization_pending"}`)
+	tests := []struct {
+		name       string
+		replies    []deviceTokenReply
+		expiresIn  int
+		wantStatus string
+		wantPolls  int32
+	}{
+		{
+			name:       "authorized after pending",
+			replies:    []deviceTokenReply{pending, pending, authorized},
+			wantStatus: deviceLoginAuthorized,
+			wantPolls:  3,
+		},
+		{
+			name:       "retries network and server errors",
+			replies:    []deviceTokenReply{deviceStatus(http.StatusBadGateway), deviceDrop, deviceStatus(http.StatusServiceUnavailable), authorized},
+			wantStatus: deviceLoginAuthorized,
+			wantPolls:  4,
+		},
+		{
+			name:       "slow down",
+			replies:    []deviceTokenReply{deviceJSON(`{"error":"slow_down","interval":2}`), authorized},
+			wantStatus: deviceLoginAuthorized,
+			wantPolls:  2,
+		},
+		{
+			name:       "server errors until the code expires",
+			replies:    []deviceTokenReply{deviceStatus(http.StatusInternalServerError)},
+			expiresIn:  100,
+			wantStatus: deviceLoginExpired,
+		},
+		{
+			name:       "client error is not retried",
+			replies:    []deviceTokenReply{deviceStatus(http.StatusBadRequest), authorized},
+			wantStatus: deviceLoginFailed,
+			wantPolls:  1,
+		},
+		{
+			name:       "denied",
+			replies:    []deviceTokenReply{deviceJSON(`{"error":"access_denied","error_description":"denied"}`)},
+			wantStatus: deviceLoginDenied,
+			wantPolls:  1,
+		},
+		{
+			name:       "expired token",
+			replies:    []deviceTokenReply{pending, deviceJSON(`{"error":"expired_token"}`)},
+			wantStatus: deviceLoginExpired,
+			wantPolls:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, polls := newDeviceFlowServer(t, tt.replies)
+			expiresIn := tt.expiresIn
+			if expiresIn == 0 {
+				expiresIn = 5000
+			}
+			code := &deviceCodeResponse{DeviceCode: "device-code", UserCode: "ABCD-1234", Interval: 1, ExpiresIn: expiresIn}
+
+			// Read the login state while the poller signs in; run with -race.
+			done := make(chan struct{})
+			var readers sync.WaitGroup
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				for {
+					select {
+					case <-done:
+						return
+					default:
+						app.GetGithubLoginStatus()
+						app.ListGithubAccounts()
+					}
+				}
+			}()
+			event := app.pollDeviceLogin(context.Background(), "client-id", code, DeviceLoginRequest{Host: defaultGithubHost})
+			close(done)
+			readers.Wait()
+
+			if event.Status != tt.wantStatus {
+				t.Fatalf("status = %q (%s), want %q", event.Status, event.Message, tt.wantStatus)
+			}
+			if tt.wantPolls > 0 && atomic.LoadInt32(polls) != tt.wantPolls {
+				t.Errorf("polls = %d, want %d", atomic.LoadInt32(polls), tt.wantPolls)
+			}
+			status := app.GetGithubLoginStatus()
+			if signedIn := tt.wantStatus == deviceLoginAuthorized; status.Authenticated != signedIn {
+				t.Errorf("Authenticated = %v, want %v", status.Authenticated, signedIn)
+			}
+			if status.Authenticated && (status.User.Login != "octocat" || event.Response == nil || event.Response.AccountID != "github.com/octocat") {
+				t.Errorf("signed in as %+v, response %+v", status.User, event.Response)
+			}
+		})
+	}
+}
+
+func TestPollDeviceLoginCancelled(t *testing.T) {
+	previous := devicePollUnit
+	devicePollUnit = time.Millisecond
+	t.Cleanup(func() { devicePollUnit = previous })
+
+	app, _ := newDeviceFlowServer(t, []deviceTokenReply{deviceJSON(`{"error":"authorization_pending"}`)})
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	code := &deviceCodeResponse{DeviceCode: "device-code", Interval: 1, ExpiresIn: 5000}
+	if event := app.pollDeviceLogin(ctx, "client-id", code, DeviceLoginRequest{}); event.Status != deviceLoginCancelled {
+		t.Fatalf("status = %q, want %q", event.Status, deviceLoginCancelled)
+	}
+}
+
+func TestFinishDeviceLogin(t *testing.T) {
+	app := NewApp()
+	_, cancelOld := context.WithCancel(context.Background())
+	ctx, cancelNew := context.WithCancel(context.Background())
+	app.cancelDeviceLogin, app.deviceLoginID = cancelNew, 2
+
+	app.finishDeviceLogin(1, cancelOld)
+	if app.cancelDeviceLogin == nil || ctx.Err() != nil {
+		t.Fatal("a replaced poll cleared the login that replaced it")
+	}
+	app.finishDeviceLogin(2, cancelNew)
+	if app.cancelDeviceLogin != nil {
+		t.Error("the cancel func of a finished poll is still set")
+	}
+	if ctx.Err() == nil {
+		t.Error("the context of a finished poll was not released")
+	}
+}
+
+func TestNextDeviceBackoff(t *testing.T) {
+	tests := []struct {
+		wait time.Duration
+		want time.Duration
+	}{
+		{5 * time.Second, 10 * time.Second},
+		{20 * time.Second, 40 * time.Second},
+		{40 * time.Second, maxDevicePollBackoff * time.Second},
+		{maxDevicePollBackoff * time.Second, maxDevicePollBackoff * time.Second},
+	}
+	for _, tt := range tests {
+		if got := nextDeviceBackoff(tt.wait); got != tt.want {
+			t.Errorf("nextDeviceBackoff(%s) = %s, want %s", tt.wait, got, tt.want)
+		}
+	}
+}
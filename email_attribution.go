@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// GithubEmail is an address from /user/emails as shown in the email picker.
+type GithubEmail struct {
+	Email      string `json:"email"`
+	Primary    bool   `json:"primary"`
+	Verified   bool   `json:"verified"`
+	Visibility string `json:"visibility"` // "public", "private" or empty
+}
+
+type GithubEmailList struct {
+	Emails []GithubEmail `json:"emails"`
+	// NoReplyEmail is the ID-based noreply address; GitHub always attributes commits made with it.
+	NoReplyEmail string `json:"noReplyEmail"`
+}
+
+type CommitEmailCheck struct {
+	Email      string `json:"email"`
+	Attributed bool   `json:"attributed"`
+	Warning    string `json:"warning,omitempty"`
+}
+
+// ListGithubEmails returns the signed-in user's email addresses and noreply address.
+func (a *App) ListGithubEmails() (*GithubEmailList, error) {
+	session := a.session()
+	if err := session.checkUserSession("list email addresses"); err != nil {
+		return nil, err
+	}
+
+	entries, err := a.fetchGithubEmails(session.Host, session.Token)
+	if err != nil {
+		return nil, err
+	}
+	list := &GithubEmailList{Emails: []GithubEmail{}, NoReplyEmail: session.noReplyEmail()}
+	for _, entry := range entries {
+		list.Emails = append(list.Emails, GithubEmail(entry))
+	}
+	return list, nil
+}
+
+// CheckCommitEmail reports whether GitHub will count commits authored with email towards
+// the signed-in user's contribution graph.
+func (a *App) CheckCommitEmail(email string) (*CommitEmailCheck, error) {
+	session := a.session()
+	if err := session.checkUserSession("check the commit email"); err != nil {
+		return nil, err
+	}
+	email = strings.TrimSpace(email)
+	warning, err := a.commitEmailWarning(session, email)
+	if err != nil {
+		return nil, err
+	}
+	return &CommitEmailCheck{Email: email, Attributed: warning == "", Warning: warning}, nil
+}
+
+func (s githubSession) checkUserSession(action string) error {
+	if !s.signedIn() {
+		return fmt.Errorf("GitHub login is required to %s", action)
+	}
+	if s.App != nil {
+		return fmt.Errorf("GitHub App installations have no email addresses; sign in as a user to %s", action)
+	}
+	return nil
+}
+
+// noReplyEmail returns the "<id>+<login>@users.noreply.<host>" address of the signed-in
+// user, or the older login-only form when the user ID is unknown.
+func (s githubSession) noReplyEmail() string {
+	if s.User == nil {
+		return ""
+	}
+	return noReplyEmailFor(s.Host, s.User)
+}
+
+func noReplyEmailFor(host string, user *GithubUserProfile) string {
+	if host == "" {
+		host = defaultGithubHost
+	}
+	if user.ID > 0 {
+		return fmt.Sprintf("%d+%s@users.noreply.%s", user.ID, user.Login, host)
+	}
+	return fmt.Sprintf("%s@users.noreply.%s", user.Login, host)
+}
+
+// commitEmailWarning explains why commits authored with email won't show up on the
+// signed-in user's graph. It returns "" when they will, or when that can't be determined.
+func (a *App) commitEmailWarning(session githubSession, email string) (string, error) {
+	if email == "" {
+		return "", fmt.Errorf("email cannot be empty")
+	}
+	user := session.User
+	if strings.EqualFold(email, noReplyEmailFor(session.Host, user)) ||
+		strings.EqualFold(email, noReplyEmailFor(session.Host, &GithubUserProfile{Login: user.Login})) {
+		return "", nil
+	}
+
+	entries, err := a.fetchGithubEmails(session.Host, session.Token)
+	if err != nil {
+		// Tokens without the user:email scope can't list addresses; don't block on that.
+		if a.ctx != nil {
+			runtime.LogWarningf(a.ctx, "cannot verify commit email: %v", err)
+		}
+		return "", nil
+	}
+
+	for _, entry := range entries {
+		if !strings.EqualFold(entry.Email, email) {
+			continue
+		}
+		if !entry.Verified {
+			return fmt.Sprintf("%s is not verified on GitHub; commits authored with it won't count towards %s's contributions until it is verified", email, user.Login), nil
+		}
+		return "", nil
+	}
+	return fmt.Sprintf("%s is not linked to the GitHub account %s; commits authored with it won't count towards its contributions (use %s or add the address to the account)", email, user.Login, session.noReplyEmail()), nil
+}
+
+// unattendedCommitEmail picks the commit email when nobody can be asked about one GitHub
+// won't attribute: an address the request names is used as given, one taken from the
+// account falls back to the noreply address.
+func unattendedCommitEmail(warning, email string, explicit bool, noReply string) (string, error) {
+	if explicit {
+		return email, nil
+	}
+	if noReply == "" {
+		return "", fmt.Errorf("%s", warning)
+	}
+	return noReply, nil
+}
+
+// confirmCommitEmail asks whether to generate anyway when commits won't be attributed.
+func (a *App) confirmCommitEmail(warning string) (bool, error) {
+	if a.ctx == nil {
+		return false, fmt.Errorf("%s", warning)
+	}
+	choice, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:          runtime.QuestionDialog,
+		Title:         "提交邮箱无法计入贡献",
+		Message:       warning + "\n\n仍要继续生成吗？",
+		Buttons:       []string{"Yes", "No"},
+		DefaultButton: "No",
+		CancelButton:  "No",
+	})
+	if err != nil {
+		return false, fmt.Errorf("open confirmation dialog: %w", err)
+	}
+	return choice == "Yes", nil
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestUnattendedCommitEmail(t *testing.T) {
+	tests := []struct {
+		name     string
+		email    string
+		explicit bool
+		noReply  string
+		want     string
+		wantErr  bool
+	}{
+		{name: "explicit email is kept", email: "octo@example.com", explicit: true, noReply: "1+octocat@users.noreply.github.com", want: "octo@example.com"},
+		{name: "account email falls back to noreply", email: "old@example.com", noReply: "1+octocat@users.noreply.github.com", want: "1+octocat@users.noreply.github.com"},
+		{name: "explicit email without noreply", email: "octo@example.com", explicit: true, want: "octo@example.com"},
+		{name: "no usable identity", email: "old@example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := unattendedCommitEmail("not attributed", tt.email, tt.explicit, tt.noReply)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("unattendedCommitEmail() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("unattendedCommitEmail() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommitIdentityWithoutWindow(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`[{"email":"octo@example.com","primary":true,"verified":true}]`))
+	}))
+	acc := &githubAccount{ID: "github.com/octocat", Host: defaultGithubHost, token: "gho_octocat",
+		User: &GithubUserProfile{ID: 1, Login: "octocat", Email: "stale@example.com"}}
+	app.accounts = []*githubAccount{acc}
+	app.activeAccountID = acc.ID
+
+	tests := []struct {
+		name      string
+		req       GenerateRepoRequest
+		wantEmail string
+	}{
+		{name: "account email not linked", wantEmail: "1+octocat@users.noreply.github.com"},
+		{name: "explicit email not linked", req: GenerateRepoRequest{GithubEmail: "other@example.com"}, wantEmail: "other@example.com"},
+		{name: "linked email", req: GenerateRepoRequest{GithubEmail: "octo@example.com"}, wantEmail: "octo@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, email, err := app.commitIdentity(tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if email != tt.wantEmail {
+				t.Errorf("commitIdentity() email = %q, want %q", email, tt.wantEmail)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type ExportFastImportResponse struct {
+	StreamPath  string   `json:"streamPath"`
+	ScriptPaths []string `json:"scriptPaths"` // a POSIX shell script and a Windows batch file
+	CommitCount int      `json:"commitCount"`
+}
+
+// ExportFastImport writes the fast-import stream GenerateRepo would feed to git, and
+// scripts that replay it into a new repository. Running
+// `git init && git fast-import < wall.fi` on another machine reproduces the same commits.
+func (a *App) ExportFastImport(req GenerateRepoRequest) (*ExportFastImportResponse, error) {
+	if len(req.Contributions) == 0 {
+		return nil, fmt.Errorf("no contributions supplied")
+	}
+	location, err := commitLocation(req.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	// Named, branched and licensed the way GenerateRepo would, so the stream reproduces the
+	// very commits generation pushes.
+	session := a.session()
+	login := strings.TrimSpace(req.GithubUsername)
+	if session.User != nil {
+		login = session.User.Login
+	}
+	remote, err := requestedRemoteRepo(req, login)
+	if err != nil {
+		return nil, err
+	}
+	username, email, err := a.commitIdentity(req)
+	if err != nil {
+		return nil, err
+	}
+	var auth githubAuth
+	if remote != nil {
+		if auth, err = a.accountGithubAuth(session.AccountID); err != nil {
+			return nil, err
+		}
+	}
+	history, err := a.newContributionHistory(req, remote, session, auth, username, email, location)
+	if err != nil {
+		return nil, err
+	}
+	repoName := history.RepoName
+
+	stream, commitCount, err := buildFastImportStream(history)
+	if err != nil {
+		return nil, err
+	}
+	if commitCount == 0 {
+		return nil, fmt.Errorf("no commits to generate")
+	}
+
+	streamPath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出 fast-import 流",
+		DefaultFilename: repoName + ".fi",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "fast-import 流 (*.fi)", Pattern: "*.fi"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if streamPath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+
+	if err := os.WriteFile(streamPath, stream.Bytes(), 0o644); err != nil {
+		return nil, fmt.Errorf("write fast-import stream: %w", err)
+	}
+	scriptPaths, err := writeFastImportScripts(streamPath, repoName, history.Branch)
+	if err != nil {
+		return nil, err
+	}
+	return &ExportFastImportResponse{StreamPath: streamPath, ScriptPaths: scriptPaths, CommitCount: commitCount}, nil
+}
+
+// writeFastImportScripts writes replay scripts next to the stream, named after it. They
+// take an optional target directory, defaulting to repoName, and check out branch.
+func writeFastImportScripts(streamPath, repoName, branch string) ([]string, error) {
+	base := strings.TrimSuffix(streamPath, filepath.Ext(streamPath))
+	streamFile := filepath.Base(streamPath)
+	shellFile, batchFile := filepath.Base(base)+".sh", filepath.Base(base)+".cmd"
+	// Quoting can't carry line breaks through the comments, nor a double quote through cmd.exe.
+	for _, value := range []string{streamFile, repoName, branch} {
+		if strings.ContainsRune(value, '"') || strings.IndexFunc(value, unicode.IsControl) >= 0 {
+			return nil, fmt.Errorf("%q can't be used in a replay script; choose another name", value)
+		}
+	}
+
+	shell := fmt.Sprintf(`#!/bin/sh
+# Recreates the GreenWall repository from %[1]s.
+# Usage: sh %[2]s [directory]
+set -e
+stream="$(cd "$(dirname "$0")" && pwd)/"%[1]s
+default_dir=%[3]s
+dir="${1:-$default_dir}"
+branch=%[4]s
+if [ -e "$dir" ]; then
+	echo "$dir already exists" >&2
+	exit 1
+fi
+git init --quiet "$dir"
+cd "$dir"
+git fast-import --quiet < "$stream"
+git checkout --quiet -f "$branch"
+echo "Created $(git rev-list --count "$branch") commits in $dir"
+`, shellQuote(streamFile), shellQuote(shellFile), shellQuote(repoName), shellQuote(branch))
+
+	batch := strings.ReplaceAll(fmt.Sprintf(`@echo off
+rem Recreates the GreenWall repository from %[1]s.
+rem Usage: %[2]s [directory]
+setlocal
+set "stream=%%~dp0%[1]s"
+set "dir=%%~1"
+if "%%dir%%"=="" set "dir=%[3]s"
+set "branch=%[4]s"
+if exist "%%dir%%" (
+  echo %%dir%% already exists 1>&2
+  exit /b 1
+)
+git init --quiet "%%dir%%" || exit /b 1
+cd /d "%%dir%%" || exit /b 1
+git fast-import --quiet < "%%stream%%" || exit /b 1
+git checkout --quiet -f "%%branch%%" || exit /b 1
+echo Created the repository in %%dir%%
+`, batchEscape(streamFile), batchEscape(batchFile), batchEscape(repoName), batchEscape(branch)), "\n", "\r\n")
+
+	scripts := []struct{ path, content string }{
+		{base + ".sh", shell},
+		{base + ".cmd", batch},
+	}
+	paths := make([]string, 0, len(scripts))
+	for _, script := range scripts {
+		if err := os.WriteFile(script.path, []byte(script.content), 0o755); err != nil {
+			return nil, fmt.Errorf("write replay script: %w", err)
+		}
+		paths = append(paths, script.path)
+	}
+	return paths, nil
+}
+
+// shellQuote quotes s as a single word for a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// batchEscape keeps cmd.exe from expanding percent signs in s. Inside double quotes nothing
+// else is special, and s can't contain a double quote.
+func batchEscape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
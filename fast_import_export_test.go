@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildFastImportStreamIsDeterministic(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skip("time zone data is not available")
+	}
+	history := contributionHistory{
+		RepoName: "wall-2024",
+		Username: "octocat",
+		Email:    "octocat@users.noreply.github.com",
+		Branch:   "trunk",
+		Year:     2024,
+		Location: tokyo,
+		License:  "MIT License\n",
+		Contributions: []ContributionDay{
+			{Date: "2024-03-02", Count: 2},
+			{Date: "2024-01-15", Count: 1},
+			{Date: "2024-02-01", Count: 0},
+		},
+	}
+
+	first, commits, err := buildFastImportStream(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Reordered input describes the same history.
+	history.Contributions = []ContributionDay{history.Contributions[2], history.Contributions[1], history.Contributions[0]}
+	second, _, err := buildFastImportStream(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(first.Bytes(), second.Bytes()) {
+		t.Fatal("the same history produced different streams")
+	}
+
+	stream := first.String()
+	if commits != 3 || strings.Count(stream, "commit refs/heads/trunk\n") != 3 {
+		t.Errorf("stream has %d commits on trunk, want 3", commits)
+	}
+	// The first commit is dated at noon in Tokyo.
+	noon := time.Date(2024, 1, 15, 12, 0, 0, 0, tokyo).Unix()
+	if !strings.Contains(stream, "author octocat <octocat@users.noreply.github.com> "+strconv.FormatInt(noon, 10)+" +0900\n") {
+		t.Error("the first commit is not dated at noon in the history's time zone")
+	}
+	if !strings.Contains(stream, " LICENSE\n") {
+		t.Error("the LICENSE file is missing")
+	}
+
+	history.Location = time.UTC
+	utc, _, err := buildFastImportStream(history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(first.Bytes(), utc.Bytes()) {
+		t.Error("the time zone doesn't change the stream")
+	}
+}
+
+func TestNewContributionHistoryFollowsRemoteRepo(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-OAuth-Scopes", "repo")
+		json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "login": "octocat", "name": "Octo Cat", "email": "octocat@example.com"})
+	})
+	mux.HandleFunc("/licenses/mit", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{"body": "Copyright (c) [year] [fullname]\n"})
+	})
+	app, _ := newTestApp(t, mux)
+	if _, err := app.signInWithToken(defaultGithubHost, "ghp_test", false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	req := GenerateRepoRequest{
+		Year:       2024,
+		RepoName:   "local name",
+		RemoteRepo: &RemoteRepoOptions{Enabled: true, Name: "{login}-wall-{year}", DefaultBranch: "trunk", LicenseTemplate: "MIT"},
+	}
+	remote, err := requestedRemoteRepo(req, "octocat")
+	if err != nil {
+		t.Fatal(err)
+	}
+	history, err := app.newContributionHistory(req, remote, app.session(), githubAuth{Host: defaultGithubHost}, "octocat", "octocat@example.com", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if history.RepoName != "octocat-wall-2024" || history.Branch != "trunk" || history.License != "Copyright (c) 2024 Octo Cat\n" {
+		t.Errorf("history = %q on %q with license %q", history.RepoName, history.Branch, history.License)
+	}
+
+	local, err := app.newContributionHistory(GenerateRepoRequest{Year: 2024}, nil, app.session(), githubAuth{}, "octocat", "", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if local.RepoName != "octocat-2024" || local.Branch != defaultBranchName || local.License != "" {
+		t.Errorf("local history = %q on %q with license %q", local.RepoName, local.Branch, local.License)
+	}
+}
+
+func TestFastImportScriptsQuoteNames(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	streamPath := filepath.Join(dir, "it's $(wall) & more.fi")
+	stream, commits, err := buildFastImportStream(contributionHistory{
+		RepoName:      "wall",
+		Username:      "octocat",
+		Email:         "octocat@example.com",
+		Branch:        "it's-$main",
+		Contributions: []ContributionDay{{Date: "2024-01-01", Count: 2}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(streamPath, stream.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	paths, err := writeFastImportScripts(streamPath, "wall", "it's-$main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	batch, err := os.ReadFile(paths[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(batch, []byte(`set "branch=it's-$main"`+"\r\n")) {
+		t.Errorf("batch script doesn't set the branch verbatim:\n%s", batch)
+	}
+
+	cmd := exec.Command("sh", paths[0], filepath.Join(dir, "replayed"))
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("replay script failed: %v\n%s", err, out)
+	}
+	if want := "Created 2 commits in "; !strings.Contains(string(out), want) || commits != 2 {
+		t.Errorf("replay script said %q, want %q", out, want)
+	}
+
+	if _, err := writeFastImportScripts(filepath.Join(dir, "a\"b.fi"), "wall", "main"); err == nil {
+		t.Error("a stream name with a double quote was accepted")
+	}
+}
@@ -104,6 +104,16 @@ const AppLayout: React.FC<AppLayoutProps> = ({ contributions }) => {
         } else {
           setGithubUser(null);
         }
+
+        // Tokens left in plaintext by older versions are encrypted as soon as the user picks a
+        // passphrase; until then this asks again on every start.
+        const storage = await mod.GetTokenStorageStatus();
+        if (storage.needsMigration) {
+          const passphrase = window.prompt(storage.warning ?? '');
+          if (passphrase) {
+            await mod.ConfigureTokenStorage({ backend: 'passphrase', passphrase });
+          }
+        }
       } catch (error) {
         console.error('Failed to fetch GitHub login status:', error);
       }
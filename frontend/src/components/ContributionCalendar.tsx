@@ -2,6 +2,7 @@ import React from 'react';
 import clsx from 'clsx';
 import {
   useContributionEditor,
+  type DuplicatePolicy,
   type OneDay,
   type PenIntensity,
 } from '../hooks/useContributionEditor';
@@ -21,6 +22,8 @@ function calculateLevel(count: number): 0 | 1 | 2 | 3 | 4 {
   return 0;
 }
 
+const DUPLICATE_POLICIES: DuplicatePolicy[] = ['last', 'first', 'sum', 'max', 'error'];
+
 type IconProps = {
   className?: string;
 };
@@ -293,13 +296,17 @@ function ContributionCalendar({
     handleTileMouseUp,
     reset,
     fillAllGreen,
-    exportContributions,
+    exportDesign,
     importContributions,
+    duplicatePolicy,
+    setDuplicatePolicy,
     openRemoteModal,
     closeRemoteModal,
     submitRemoteModal,
     isRemoteModalOpen,
     remoteRepoDefaultName,
+    remoteRepoDefaultDescription,
+    remoteRepoDefaultPrivate,
     isGeneratingRepo,
     toast,
   } = useContributionEditor({
@@ -617,11 +624,24 @@ function ContributionCalendar({
           <div className="workspace__brand">GreenWall</div>
 
           <div className="workspace__toolbar-group workspace__toolbar-group--end">
+            <select
+              className="workspace__select"
+              value={duplicatePolicy}
+              onChange={(event) => setDuplicatePolicy(event.target.value as DuplicatePolicy)}
+              aria-label={t('labels.duplicatePolicy')}
+              title={t('labels.duplicatePolicy')}
+            >
+              {DUPLICATE_POLICIES.map((policy) => (
+                <option key={policy} value={policy}>
+                  {t(`duplicatePolicies.${policy}`)}
+                </option>
+              ))}
+            </select>
             <button type="button" className="workspace__command" onClick={importContributions}>
               <ImportIcon className="workspace__command-icon" />
               <span>{t('buttons.import')}</span>
             </button>
-            <button type="button" className="workspace__command" onClick={exportContributions}>
+            <button type="button" className="workspace__command" onClick={exportDesign}>
               <ExportIcon className="workspace__command-icon" />
               <span>{t('buttons.export')}</span>
             </button>
@@ -845,8 +865,8 @@ function ContributionCalendar({
         <RemoteRepoModal
           open={isRemoteModalOpen}
           defaultName={remoteRepoDefaultName}
-          defaultDescription=""
-          defaultPrivate
+          defaultDescription={remoteRepoDefaultDescription}
+          defaultPrivate={remoteRepoDefaultPrivate}
           isSubmitting={isGeneratingRepo}
           onClose={closeRemoteModal}
           onSubmit={submitRemoteModal}
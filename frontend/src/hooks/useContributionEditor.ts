@@ -1,5 +1,10 @@
 import React from 'react';
-import { ExportContributions, GenerateRepo, ImportContributions } from '../../wailsjs/go/main/App';
+import {
+  ExportDesign,
+  ExportRepoBundle,
+  GenerateRepo,
+  ImportContributions,
+} from '../../wailsjs/go/main/App';
 import { main } from '../../wailsjs/go/models';
 import type { RemoteRepoPayload } from '../components/RemoteRepoModal';
 import { getPatternById, gridToBoolean } from '../data/characterPatterns';
@@ -10,6 +15,8 @@ import { useContributionHistory } from './useContributionHistory';
 export type OneDay = { level: number; count: number; date: string };
 export type DrawMode = 'pen' | 'eraser';
 export type PenIntensity = 1 | 3 | 6 | 9;
+// How ImportContributions merges several entries for the same day.
+export type DuplicatePolicy = 'last' | 'first' | 'sum' | 'max' | 'error';
 export type ContributionBuffer = {
   width: number;
   height: number;
@@ -17,6 +24,7 @@ export type ContributionBuffer = {
 };
 
 const MIN_YEAR = 2008;
+const MAX_REPORTED_IMPORT_ISSUES = 10;
 
 function getNextContribution(current: number): number {
   if (current < 1) return 1;
@@ -70,6 +78,9 @@ export function useContributionEditor({
   const [pastePreviewActive, setPastePreviewActive] = React.useState(false);
   const [pastePreviewDates, setPastePreviewDates] = React.useState<Set<string>>(new Set());
   const [toast, setToast] = React.useState<string | null>(null);
+  // Settings of the last imported .greenwall design, kept for exporting and generating.
+  const [design, setDesign] = React.useState<main.DesignDocument | null>(null);
+  const [duplicatePolicy, setDuplicatePolicy] = React.useState<DuplicatePolicy>('last');
   const toastTimeoutRef = React.useRef<number | null>(null);
 
   const setYear = React.useCallback(
@@ -457,7 +468,7 @@ export function useContributionEditor({
     });
   }, [filteredContributions, isFutureDate, pushSnapshot, setUserContributions]);
 
-  const exportContributions = React.useCallback(async () => {
+  const exportDesign = React.useCallback(async () => {
     const contributionsToExport = filteredContributions
       .map((entry) => {
         const override = userContributions.get(entry.date);
@@ -469,34 +480,98 @@ export function useContributionEditor({
       .filter((entry) => entry.count > 0);
 
     try {
-      const payload = main.ExportContributionsRequest.createFrom({
+      const payload = main.ExportDesignRequest.createFrom({
+        title: design?.title,
+        author: design?.author,
+        year,
         contributions: contributionsToExport,
+        identity: design?.identity,
+        timezone: design?.timezone,
+        remote: design?.remote,
       });
-      const result = await ExportContributions(payload);
+      const result = await ExportDesign(payload);
       window.alert(t('messages.exportSuccess', { filePath: result.filePath }));
     } catch (error) {
-      console.error('Failed to export contributions', error);
+      console.error('Failed to export design', error);
       const message = error instanceof Error ? error.message : String(error);
       window.alert(t('messages.exportError', { message }));
     }
-  }, [filteredContributions, t, userContributions]);
+  }, [design, filteredContributions, t, userContributions, year]);
+
+  const describeImportReport = React.useCallback(
+    (report?: main.ImportReport) => {
+      const lines = [
+        t('messages.importSuccess', {
+          accepted: report?.accepted ?? 0,
+          entries: report?.entries ?? 0,
+        }),
+      ];
+      const describe = (heading: string, issues: main.ImportIssue[] | undefined) => {
+        if (!issues?.length) {
+          return;
+        }
+        lines.push('', heading);
+        issues.slice(0, MAX_REPORTED_IMPORT_ISSUES).forEach((issue) => {
+          lines.push(t('messages.importReportLine', { line: issue.line, message: issue.message }));
+        });
+        if (issues.length > MAX_REPORTED_IMPORT_ISSUES) {
+          const count = issues.length - MAX_REPORTED_IMPORT_ISSUES;
+          lines.push(t('messages.importReportMore', { count }));
+        }
+      };
+      describe(t('messages.importReportWarnings'), report?.warnings);
+      describe(t('messages.importReportFixes'), report?.fixes);
+      return lines.join('\n');
+    },
+    [t]
+  );
 
   const importContributions = React.useCallback(async () => {
     try {
-      const result = await ImportContributions();
+      // The year only filters plain contribution arrays; designs and grids keep their own dates.
+      const result = await ImportContributions(
+        main.ImportContributionsRequest.createFrom({ year, duplicatePolicy })
+      );
       const importedMap = new Map<string, number>();
       result.contributions.forEach((entry) => {
         importedMap.set(entry.date, entry.count);
       });
       pushSnapshot();
       setUserContributions(importedMap);
-      window.alert(t('messages.importSuccess'));
+      setDesign(result.design ?? null);
+      const lastImported = result.contributions[result.contributions.length - 1];
+      const importedYear =
+        result.design?.year ||
+        (result.design?.window ? getYearFromIsoDate(result.design.window.end) : 0) ||
+        (lastImported ? getYearFromIsoDate(lastImported.date) : 0);
+      if (importedYear) {
+        setYear(importedYear);
+      }
+      window.alert(describeImportReport(result.report));
     } catch (error) {
       console.error('Failed to import contributions', error);
       const message = error instanceof Error ? error.message : String(error);
       window.alert(t('messages.importError', { message }));
     }
-  }, [pushSnapshot, setUserContributions, t]);
+  }, [describeImportReport, duplicatePolicy, pushSnapshot, setUserContributions, setYear, t, year]);
+
+  const exportRepoBundle = React.useCallback(
+    async (repoPath: string) => {
+      try {
+        const result = await ExportRepoBundle(main.ExportRepoBundleRequest.createFrom({ repoPath }));
+        const size =
+          result.size >= 1024 * 1024
+            ? `${(result.size / (1024 * 1024)).toFixed(1)} MB`
+            : `${Math.max(1, Math.round(result.size / 1024))} KB`;
+        window.alert(t('messages.bundleSuccess', { bundlePath: result.bundlePath, size }));
+      } catch (error) {
+        console.error('Failed to export git bundle', error);
+        const message = error instanceof Error ? error.message : String(error);
+        window.alert(t('messages.bundleError', { message }));
+      }
+    },
+    [t]
+  );
 
   const runGenerateRepo = React.useCallback(
     async (remoteRepoOptions: RemoteRepoPayload) => {
@@ -532,7 +607,10 @@ export function useContributionEditor({
           githubEmail,
           repoName: remoteRepoOptions.name.trim(),
           contributions: contributionsForBackend,
+          timezone: design?.timezone,
+          identity: design?.identity,
           remoteRepo: {
+            ...design?.remote,
             enabled: true,
             name: remoteRepoOptions.name.trim(),
             private: remoteRepoOptions.isPrivate,
@@ -546,6 +624,9 @@ export function useContributionEditor({
             ? `${baseMessage}\nRemote repository: ${result.remoteUrl}`
             : baseMessage;
         window.alert(fullMessage);
+        if (window.confirm(t('messages.bundleOffer'))) {
+          await exportRepoBundle(result.repoPath);
+        }
       } catch (error) {
         console.error('Failed to generate repository', error);
         const message = error instanceof Error ? error.message : String(error);
@@ -554,7 +635,7 @@ export function useContributionEditor({
         setIsGeneratingRepo(false);
       }
     },
-    [filteredContributions, githubUser, t, userContributions, year]
+    [design, exportRepoBundle, filteredContributions, githubUser, t, userContributions, year]
   );
 
   const openRemoteModal = React.useCallback(() => {
@@ -587,10 +668,14 @@ export function useContributionEditor({
     [filteredContributions, userContributions]
   );
 
-  const remoteRepoDefaultName = React.useMemo(
-    () => (githubUser?.login?.trim() ? `${githubUser.login.trim()}-${year}` : `green-wall-${year}`),
-    [githubUser, year]
-  );
+  const remoteRepoDefaultName = React.useMemo(() => {
+    // A saved name with {login} or {year} placeholders would not pass the name check.
+    const designName = design?.remote?.name?.trim() ?? '';
+    if (designName && !designName.includes('{')) {
+      return designName;
+    }
+    return githubUser?.login?.trim() ? `${githubUser.login.trim()}-${year}` : `green-wall-${year}`;
+  }, [design, githubUser, year]);
 
   const getTooltip = React.useCallback(
     (oneDay: OneDay) => {
@@ -890,13 +975,17 @@ export function useContributionEditor({
     handleTileMouseUp,
     reset,
     fillAllGreen,
-    exportContributions,
+    exportDesign,
     importContributions,
+    duplicatePolicy,
+    setDuplicatePolicy,
     openRemoteModal,
     closeRemoteModal,
     submitRemoteModal,
     isRemoteModalOpen,
     remoteRepoDefaultName,
+    remoteRepoDefaultDescription: design?.remote?.description ?? '',
+    remoteRepoDefaultPrivate: design?.remote?.private ?? true,
     isGeneratingRepo,
     toast,
     hasSelectionBuffer: Boolean(selectionBuffer),
@@ -12,6 +12,14 @@ type TranslationDict = {
     drawMode: string;
     penIntensity: string;
     language: string;
+    duplicatePolicy: string;
+  };
+  duplicatePolicies: {
+    last: string;
+    first: string;
+    sum: string;
+    max: string;
+    error: string;
   };
   placeholders: {
     githubUsername: string;
@@ -56,7 +64,14 @@ type TranslationDict = {
     exportSuccess: string;
     exportError: string;
     importSuccess: string;
+    importReportWarnings: string;
+    importReportFixes: string;
+    importReportLine: string;
+    importReportMore: string;
     importError: string;
+    bundleOffer: string;
+    bundleSuccess: string;
+    bundleError: string;
     remoteLoginRequired: string;
     cutSuccess: string;
     copySuccess: string;
@@ -204,6 +219,14 @@ const translations: Record<Language, TranslationDict> = {
       drawMode: 'Draw Mode',
       penIntensity: 'Pen Intensity',
       language: 'Language',
+      duplicatePolicy: 'Days listed more than once on import',
+    },
+    duplicatePolicies: {
+      last: 'Keep the last',
+      first: 'Keep the first',
+      sum: 'Add them up',
+      max: 'Keep the largest',
+      error: 'Refuse the file',
     },
     placeholders: {
       githubUsername: 'octocat',
@@ -237,8 +260,8 @@ const translations: Record<Language, TranslationDict> = {
       allGreen: 'Set all contributions to green',
       reset: 'Clear all customised contribution data',
       generate: 'Create a local git repository matching this contribution calendar',
-      export: 'Export current contributions to a JSON file',
-      import: 'Import contributions from a JSON file',
+      export: 'Save the design and its settings to a .greenwall file',
+      import: 'Import a .greenwall design, a JSON file or a text grid',
       copyMode: 'Copy mode - select area then press Ctrl+C to copy',
     },
     messages: {
@@ -246,10 +269,18 @@ const translations: Record<Language, TranslationDict> = {
         'Please provide a GitHub username and email before generating a repository.',
       noContributions: 'No contributions to generate. Add contributions first.',
       generateRepoError: 'Failed to generate repository: {{message}}',
-      exportSuccess: 'Contributions exported to {{filePath}}',
-      exportError: 'Failed to export contributions: {{message}}',
-      importSuccess: 'Contributions imported successfully',
+      exportSuccess: 'Design exported to {{filePath}}',
+      exportError: 'Failed to export design: {{message}}',
+      importSuccess: 'Imported {{accepted}} day(s) from {{entries}} entries',
+      importReportWarnings: 'Left out:',
+      importReportFixes: 'Merged or tidied:',
+      importReportLine: 'Line {{line}}: {{message}}',
+      importReportMore: '…and {{count}} more',
       importError: 'Failed to import contributions: {{message}}',
+      bundleOffer:
+        'Also save the repository as a git bundle? You can move the bundle to another machine and push it from there.',
+      bundleSuccess: 'Verified git bundle saved to {{bundlePath}} ({{size}})',
+      bundleError: 'Failed to export git bundle: {{message}}',
       remoteLoginRequired:
         'Please sign in with your GitHub token before creating a remote repository.',
       cutSuccess: 'Cut success: {{count}} colored cells',
@@ -401,6 +432,14 @@ const translations: Record<Language, TranslationDict> = {
       drawMode: '绘制模式',
       penIntensity: '画笔强度',
       language: '语言',
+      duplicatePolicy: '导入时重复出现的日期',
+    },
+    duplicatePolicies: {
+      last: '保留最后一条',
+      first: '保留第一条',
+      sum: '相加',
+      max: '保留最大值',
+      error: '拒绝导入',
     },
     placeholders: {
       githubUsername: 'octocat',
@@ -434,18 +473,25 @@ const translations: Record<Language, TranslationDict> = {
       allGreen: '将所有贡献设置为绿色',
       reset: '清除所有自定义贡献数据',
       generate: '创建与当前贡献图匹配的本地 Git 仓库',
-      export: '导出当前贡献数据到 JSON 文件',
-      import: '从 JSON 文件导入贡献数据',
+      export: '将设计及其设置保存为 .greenwall 文件',
+      import: '导入 .greenwall 设计、JSON 文件或文本网格',
       copyMode: '复制模式 - 选中区域后按 Ctrl+C 复制',
     },
     messages: {
       generateRepoMissing: '请先填写 GitHub 用户名和邮箱，然后再生成仓库。',
       noContributions: '没有可生成的贡献，请先添加贡献。',
       generateRepoError: '生成仓库失败：{{message}}',
-      exportSuccess: '贡献数据已导出到 {{filePath}}',
-      exportError: '导出贡献数据失败：{{message}}',
-      importSuccess: '贡献数据已成功导入',
+      exportSuccess: '设计已导出到 {{filePath}}',
+      exportError: '导出设计失败：{{message}}',
+      importSuccess: '已从 {{entries}} 条记录导入 {{accepted}} 天的贡献数据',
+      importReportWarnings: '未导入：',
+      importReportFixes: '已合并或整理：',
+      importReportLine: '第 {{line}} 行：{{message}}',
+      importReportMore: '……另有 {{count}} 条',
       importError: '导入贡献数据失败：{{message}}',
+      bundleOffer: '是否同时将仓库保存为 Git bundle？可以把它拷贝到其他机器后再推送。',
+      bundleSuccess: '已校验的 Git bundle 已保存到 {{bundlePath}}（{{size}}）',
+      bundleError: '导出 Git bundle 失败：{{message}}',
       remoteLoginRequired: '请先登录 GitHub 再创建远程仓库。',
       cutSuccess: '剪切成功：{{count}} 个涂色格子',
       copySuccess: '复制成功：{{count}} 个涂色格子',
@@ -2,18 +2,80 @@
 // This file is automatically generated. DO NOT EDIT
 import {main} from '../models';
 
+export function ApplyManagedRepoAction(arg1:main.ManagedRepoActionRequest):Promise<Array<main.ManagedRepoActionResult>>;
+
+export function AuthenticateWithGithubApp(arg1:main.GithubAppAuthRequest):Promise<main.GithubAuthResponse>;
+
 export function AuthenticateWithToken(arg1:main.GithubAuthRequest):Promise<main.GithubAuthResponse>;
 
+export function CancelGithubDeviceLogin():Promise<void>;
+
+export function CheckCommitEmail(arg1:string):Promise<main.CommitEmailCheck>;
+
 export function CheckGitInstalled():Promise<main.CheckGitInstalledResponse>;
 
+export function CheckRepoName(arg1:main.RepoNameCheckRequest):Promise<main.RepoNameCheckResponse>;
+
+export function ConfigureTokenStorage(arg1:main.TokenStorageRequest):Promise<void>;
+
+export function DiscardPendingGeneration():Promise<void>;
+
+export function ExportAuditLog(arg1:main.AuditLogQuery):Promise<main.ExportAuditLogResponse>;
+
+export function ExportCalendarImage(arg1:main.RenderCalendarRequest):Promise<main.RenderCalendarResponse>;
+
 export function ExportContributions(arg1:main.ExportContributionsRequest):Promise<main.ExportContributionsResponse>;
 
+export function ExportContributionsCSV(arg1:main.ExportContributionsCSVRequest):Promise<main.ExportContributionsResponse>;
+
+export function ExportContributionsGrid(arg1:main.ExportContributionsGridRequest):Promise<main.ExportContributionsResponse>;
+
+export function ExportDesign(arg1:main.ExportDesignRequest):Promise<main.ExportContributionsResponse>;
+
+export function ExportFastImport(arg1:main.GenerateRepoRequest):Promise<main.ExportFastImportResponse>;
+
+export function ExportRepoBundle(arg1:main.ExportRepoBundleRequest):Promise<main.ExportRepoBundleResponse>;
+
 export function GenerateRepo(arg1:main.GenerateRepoRequest):Promise<main.GenerateRepoResponse>;
 
 export function GetGithubLoginStatus():Promise<main.GithubLoginStatus>;
 
-export function ImportContributions():Promise<main.ImportContributionsResponse>;
+export function GetNetworkSettings():Promise<main.NetworkSettings>;
+
+export function GetPendingGeneration():Promise<main.PendingGeneration>;
+
+export function GetTokenStorageStatus():Promise<main.TokenStorageStatus>;
+
+export function ImportContributions(arg1:main.ImportContributionsRequest):Promise<main.ImportContributionsResponse>;
+
+export function ImportContributionsCSV(arg1:main.CSVImportRequest):Promise<main.CSVImportResponse>;
+
+export function ImportGitHistory(arg1:main.GitHistoryImportRequest):Promise<main.GitHistoryImportResponse>;
+
+export function ImportImage(arg1:main.ImageImportRequest):Promise<main.ImageImportResponse>;
+
+export function ListGithubAccounts():Promise<Array<main.GithubAccount>>;
+
+export function ListGithubEmails():Promise<main.GithubEmailList>;
+
+export function ListGreenWallRepos():Promise<Array<main.ManagedRepo>>;
 
 export function LogoutGithub():Promise<void>;
 
+export function QueryAuditLog(arg1:main.AuditLogQuery):Promise<Array<main.AuditEntry>>;
+
+export function RemoveGithubAccount(arg1:string):Promise<void>;
+
+export function RenameGithubAccount(arg1:main.RenameGithubAccountRequest):Promise<void>;
+
+export function ResumePendingGeneration():Promise<main.GenerateRepoResponse>;
+
 export function SetGitPath(arg1:main.SetGitPathRequest):Promise<main.SetGitPathResponse>;
+
+export function SetNetworkSettings(arg1:main.NetworkSettings):Promise<void>;
+
+export function StartGithubDeviceLogin(arg1:main.DeviceLoginRequest):Promise<main.DeviceLoginStart>;
+
+export function SwitchGithubAccount(arg1:string):Promise<main.GithubLoginStatus>;
+
+export function UnlockGithubToken(arg1:main.UnlockTokenRequest):Promise<main.GithubAuthResponse>;
@@ -2,18 +2,74 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApplyManagedRepoAction(arg1) {
+  return window['go']['main']['App']['ApplyManagedRepoAction'](arg1);
+}
+
+export function AuthenticateWithGithubApp(arg1) {
+  return window['go']['main']['App']['AuthenticateWithGithubApp'](arg1);
+}
+
 export function AuthenticateWithToken(arg1) {
   return window['go']['main']['App']['AuthenticateWithToken'](arg1);
 }
 
+export function CancelGithubDeviceLogin() {
+  return window['go']['main']['App']['CancelGithubDeviceLogin']();
+}
+
+export function CheckCommitEmail(arg1) {
+  return window['go']['main']['App']['CheckCommitEmail'](arg1);
+}
+
 export function CheckGitInstalled() {
   return window['go']['main']['App']['CheckGitInstalled']();
 }
 
+export function CheckRepoName(arg1) {
+  return window['go']['main']['App']['CheckRepoName'](arg1);
+}
+
+export function ConfigureTokenStorage(arg1) {
+  return window['go']['main']['App']['ConfigureTokenStorage'](arg1);
+}
+
+export function DiscardPendingGeneration() {
+  return window['go']['main']['App']['DiscardPendingGeneration']();
+}
+
+export function ExportAuditLog(arg1) {
+  return window['go']['main']['App']['ExportAuditLog'](arg1);
+}
+
+export function ExportCalendarImage(arg1) {
+  return window['go']['main']['App']['ExportCalendarImage'](arg1);
+}
+
 export function ExportContributions(arg1) {
   return window['go']['main']['App']['ExportContributions'](arg1);
 }
 
+export function ExportContributionsCSV(arg1) {
+  return window['go']['main']['App']['ExportContributionsCSV'](arg1);
+}
+
+export function ExportContributionsGrid(arg1) {
+  return window['go']['main']['App']['ExportContributionsGrid'](arg1);
+}
+
+export function ExportDesign(arg1) {
+  return window['go']['main']['App']['ExportDesign'](arg1);
+}
+
+export function ExportFastImport(arg1) {
+  return window['go']['main']['App']['ExportFastImport'](arg1);
+}
+
+export function ExportRepoBundle(arg1) {
+  return window['go']['main']['App']['ExportRepoBundle'](arg1);
+}
+
 export function GenerateRepo(arg1) {
   return window['go']['main']['App']['GenerateRepo'](arg1);
 }
@@ -22,14 +78,82 @@ export function GetGithubLoginStatus() {
   return window['go']['main']['App']['GetGithubLoginStatus']();
 }
 
-export function ImportContributions() {
-  return window['go']['main']['App']['ImportContributions']();
+export function GetNetworkSettings() {
+  return window['go']['main']['App']['GetNetworkSettings']();
+}
+
+export function GetPendingGeneration() {
+  return window['go']['main']['App']['GetPendingGeneration']();
+}
+
+export function GetTokenStorageStatus() {
+  return window['go']['main']['App']['GetTokenStorageStatus']();
+}
+
+export function ImportContributions(arg1) {
+  return window['go']['main']['App']['ImportContributions'](arg1);
+}
+
+export function ImportContributionsCSV(arg1) {
+  return window['go']['main']['App']['ImportContributionsCSV'](arg1);
+}
+
+export function ImportGitHistory(arg1) {
+  return window['go']['main']['App']['ImportGitHistory'](arg1);
+}
+
+export function ImportImage(arg1) {
+  return window['go']['main']['App']['ImportImage'](arg1);
+}
+
+export function ListGithubAccounts() {
+  return window['go']['main']['App']['ListGithubAccounts']();
+}
+
+export function ListGithubEmails() {
+  return window['go']['main']['App']['ListGithubEmails']();
+}
+
+export function ListGreenWallRepos() {
+  return window['go']['main']['App']['ListGreenWallRepos']();
 }
 
 export function LogoutGithub() {
   return window['go']['main']['App']['LogoutGithub']();
 }
 
+export function QueryAuditLog(arg1) {
+  return window['go']['main']['App']['QueryAuditLog'](arg1);
+}
+
+export function RemoveGithubAccount(arg1) {
+  return window['go']['main']['App']['RemoveGithubAccount'](arg1);
+}
+
+export function RenameGithubAccount(arg1) {
+  return window['go']['main']['App']['RenameGithubAccount'](arg1);
+}
+
+export function ResumePendingGeneration() {
+  return window['go']['main']['App']['ResumePendingGeneration']();
+}
+
 export function SetGitPath(arg1) {
   return window['go']['main']['App']['SetGitPath'](arg1);
 }
+
+export function SetNetworkSettings(arg1) {
+  return window['go']['main']['App']['SetNetworkSettings'](arg1);
+}
+
+export function StartGithubDeviceLogin(arg1) {
+  return window['go']['main']['App']['StartGithubDeviceLogin'](arg1);
+}
+
+export function SwitchGithubAccount(arg1) {
+  return window['go']['main']['App']['SwitchGithubAccount'](arg1);
+}
+
+export function UnlockGithubToken(arg1) {
+  return window['go']['main']['App']['UnlockGithubToken'](arg1);
+}
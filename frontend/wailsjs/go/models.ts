@@ -1,17 +1,100 @@
 export namespace main {
 	
-	export class CheckGitInstalledResponse {
-	    installed: boolean;
-	    version: string;
+	export class AuditEntry {
+	    // Go type: time
+	    time: any;
+	    accountId?: string;
+	    action: string;
+	    repo?: string;
+	    outcome: string;
+	    error?: string;
+	    details?: Record<string, string>;
 	
 	    static createFrom(source: any = {}) {
-	        return new CheckGitInstalledResponse(source);
+	        return new AuditEntry(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.installed = source["installed"];
-	        this.version = source["version"];
+	        this.time = this.convertValues(source["time"], null);
+	        this.accountId = source["accountId"];
+	        this.action = source["action"];
+	        this.repo = source["repo"];
+	        this.outcome = source["outcome"];
+	        this.error = source["error"];
+	        this.details = source["details"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuditLogQuery {
+	    accountId?: string;
+	    action?: string;
+	    repo?: string;
+	    outcome?: string;
+	    since?: string;
+	    until?: string;
+	    limit?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new AuditLogQuery(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accountId = source["accountId"];
+	        this.action = source["action"];
+	        this.repo = source["repo"];
+	        this.outcome = source["outcome"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.limit = source["limit"];
+	    }
+	}
+	export class CSVImportRequest {
+	    filePath?: string;
+	    delimiter?: string;
+	    hasHeader?: boolean;
+	    dateColumn?: string;
+	    valueColumn?: string;
+	    dateFormat?: string;
+	    aggregate?: string;
+	    scale?: string;
+	    maxCount?: number;
+	    decimalSeparator?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CSVImportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.delimiter = source["delimiter"];
+	        this.hasHeader = source["hasHeader"];
+	        this.dateColumn = source["dateColumn"];
+	        this.valueColumn = source["valueColumn"];
+	        this.dateFormat = source["dateFormat"];
+	        this.aggregate = source["aggregate"];
+	        this.scale = source["scale"];
+	        this.maxCount = source["maxCount"];
+	        this.decimalSeparator = source["decimalSeparator"];
 	    }
 	}
 	export class ContributionDay {
@@ -28,15 +111,29 @@ export namespace main {
 	        this.count = source["count"];
 	    }
 	}
-	export class ExportContributionsRequest {
+	export class CSVImportResponse {
+	    filePath: string;
+	    columns: string[];
+	    dateFormat: string;
+	    delimiter: string;
+	    rowsRead: number;
+	    rowsSkipped: number;
+	    warnings?: string[];
 	    contributions: ContributionDay[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ExportContributionsRequest(source);
+	        return new CSVImportResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.columns = source["columns"];
+	        this.dateFormat = source["dateFormat"];
+	        this.delimiter = source["delimiter"];
+	        this.rowsRead = source["rowsRead"];
+	        this.rowsSkipped = source["rowsSkipped"];
+	        this.warnings = source["warnings"];
 	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
 	    }
 	
@@ -58,16 +155,67 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class ExportContributionsResponse {
-	    filePath: string;
+	export class CalendarTheme {
+	    background?: string;
+	    text?: string;
+	    levels?: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new ExportContributionsResponse(source);
+	        return new CalendarTheme(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.filePath = source["filePath"];
+	        this.background = source["background"];
+	        this.text = source["text"];
+	        this.levels = source["levels"];
+	    }
+	}
+	export class CheckGitInstalledResponse {
+	    installed: boolean;
+	    version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CheckGitInstalledResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.installed = source["installed"];
+	        this.version = source["version"];
+	    }
+	}
+	export class CommitEmailCheck {
+	    email: string;
+	    attributed: boolean;
+	    warning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new CommitEmailCheck(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.email = source["email"];
+	        this.attributed = source["attributed"];
+	        this.warning = source["warning"];
+	    }
+	}
+	
+	export class DesignDay {
+	    date: string;
+	    count?: number;
+	    level?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DesignDay(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.date = source["date"];
+	        this.count = source["count"];
+	        this.level = source["level"];
 	    }
 	}
 	export class RemoteRepoOptions {
@@ -75,6 +223,14 @@ export namespace main {
 	    name: string;
 	    private: boolean;
 	    description: string;
+	    topics?: string[];
+	    homepage?: string;
+	    defaultBranch?: string;
+	    licenseTemplate?: string;
+	    disableIssues?: boolean;
+	    disableWiki?: boolean;
+	    disableProjects?: boolean;
+	    archiveAfterPush?: boolean;
 	
 	    static createFrom(source: any = {}) {
 	        return new RemoteRepoOptions(source);
@@ -86,28 +242,72 @@ export namespace main {
 	        this.name = source["name"];
 	        this.private = source["private"];
 	        this.description = source["description"];
+	        this.topics = source["topics"];
+	        this.homepage = source["homepage"];
+	        this.defaultBranch = source["defaultBranch"];
+	        this.licenseTemplate = source["licenseTemplate"];
+	        this.disableIssues = source["disableIssues"];
+	        this.disableWiki = source["disableWiki"];
+	        this.disableProjects = source["disableProjects"];
+	        this.archiveAfterPush = source["archiveAfterPush"];
 	    }
 	}
-	export class GenerateRepoRequest {
-	    year: number;
-	    githubUsername: string;
-	    githubEmail: string;
-	    repoName: string;
-	    contributions: ContributionDay[];
-	    remoteRepo?: RemoteRepoOptions;
+	export class DesignIdentity {
+	    name?: string;
+	    email?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GenerateRepoRequest(source);
+	        return new DesignIdentity(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.email = source["email"];
+	    }
+	}
+	export class DesignWindow {
+	    end: string;
+	    weeks: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DesignWindow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.end = source["end"];
+	        this.weeks = source["weeks"];
+	    }
+	}
+	export class DesignDocument {
+	    $schema?: string;
+	    version: number;
+	    title?: string;
+	    author?: string;
+	    year?: number;
+	    window?: DesignWindow;
+	    days: DesignDay[];
+	    identity?: DesignIdentity;
+	    timezone?: string;
+	    remote?: RemoteRepoOptions;
+	
+	    static createFrom(source: any = {}) {
+	        return new DesignDocument(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.$schema = source["$schema"];
+	        this.version = source["version"];
+	        this.title = source["title"];
+	        this.author = source["author"];
 	        this.year = source["year"];
-	        this.githubUsername = source["githubUsername"];
-	        this.githubEmail = source["githubEmail"];
-	        this.repoName = source["repoName"];
-	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
-	        this.remoteRepo = this.convertValues(source["remoteRepo"], RemoteRepoOptions);
+	        this.window = this.convertValues(source["window"], DesignWindow);
+	        this.days = this.convertValues(source["days"], DesignDay);
+	        this.identity = this.convertValues(source["identity"], DesignIdentity);
+	        this.timezone = source["timezone"];
+	        this.remote = this.convertValues(source["remote"], RemoteRepoOptions);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -128,66 +328,68 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class GenerateRepoResponse {
-	    repoPath: string;
-	    commitCount: number;
-	    remoteUrl?: string;
+	
+	
+	export class DeviceLoginRequest {
+	    host?: string;
+	    remember: boolean;
+	    passphrase?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GenerateRepoResponse(source);
+	        return new DeviceLoginRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.repoPath = source["repoPath"];
-	        this.commitCount = source["commitCount"];
-	        this.remoteUrl = source["remoteUrl"];
+	        this.host = source["host"];
+	        this.remember = source["remember"];
+	        this.passphrase = source["passphrase"];
 	    }
 	}
-	export class GithubAuthRequest {
-	    token: string;
-	    remember: boolean;
+	export class DeviceLoginStart {
+	    userCode: string;
+	    verificationUri: string;
+	    expiresIn: number;
+	    interval: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new GithubAuthRequest(source);
+	        return new DeviceLoginStart(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.token = source["token"];
-	        this.remember = source["remember"];
+	        this.userCode = source["userCode"];
+	        this.verificationUri = source["verificationUri"];
+	        this.expiresIn = source["expiresIn"];
+	        this.interval = source["interval"];
 	    }
 	}
-	export class GithubUserProfile {
-	    login: string;
-	    name: string;
-	    email: string;
-	    avatarUrl: string;
+	export class ExportAuditLogResponse {
+	    filePath: string;
+	    count: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new GithubUserProfile(source);
+	        return new ExportAuditLogResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.login = source["login"];
-	        this.name = source["name"];
-	        this.email = source["email"];
-	        this.avatarUrl = source["avatarUrl"];
+	        this.filePath = source["filePath"];
+	        this.count = source["count"];
 	    }
 	}
-	export class GithubAuthResponse {
-	    user?: GithubUserProfile;
-	    remembered: boolean;
+	export class ExportContributionsCSVRequest {
+	    contributions: ContributionDay[];
+	    delimiter?: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new GithubAuthResponse(source);
+	        return new ExportContributionsCSVRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.user = this.convertValues(source["user"], GithubUserProfile);
-	        this.remembered = source["remembered"];
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.delimiter = source["delimiter"];
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -208,18 +410,20 @@ export namespace main {
 		    return a;
 		}
 	}
-	export class GithubLoginStatus {
-	    authenticated: boolean;
-	    user?: GithubUserProfile;
+	export class ExportContributionsGridRequest {
+	    contributions: ContributionDay[];
+	    year?: number;
+	    window?: DesignWindow;
 	
 	    static createFrom(source: any = {}) {
-	        return new GithubLoginStatus(source);
+	        return new ExportContributionsGridRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.authenticated = source["authenticated"];
-	        this.user = this.convertValues(source["user"], GithubUserProfile);
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.year = source["year"];
+	        this.window = this.convertValues(source["window"], DesignWindow);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -240,17 +444,74 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class ExportContributionsRequest {
+	    contributions: ContributionDay[];
 	
-	export class ImportContributionsResponse {
+	    static createFrom(source: any = {}) {
+	        return new ExportContributionsRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ExportContributionsResponse {
+	    filePath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ExportContributionsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	    }
+	}
+	export class ExportDesignRequest {
+	    title?: string;
+	    author?: string;
+	    year?: number;
+	    window?: DesignWindow;
 	    contributions: ContributionDay[];
+	    storeLevels?: boolean;
+	    identity?: DesignIdentity;
+	    timezone?: string;
+	    remote?: RemoteRepoOptions;
 	
 	    static createFrom(source: any = {}) {
-	        return new ImportContributionsResponse(source);
+	        return new ExportDesignRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.title = source["title"];
+	        this.author = source["author"];
+	        this.year = source["year"];
+	        this.window = this.convertValues(source["window"], DesignWindow);
 	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.storeLevels = source["storeLevels"];
+	        this.identity = this.convertValues(source["identity"], DesignIdentity);
+	        this.timezone = source["timezone"];
+	        this.remote = this.convertValues(source["remote"], RemoteRepoOptions);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -271,33 +532,963 @@ export namespace main {
 		    return a;
 		}
 	}
+	export class ExportFastImportResponse {
+	    streamPath: string;
+	    scriptPaths: string[];
+	    commitCount: number;
 	
-	export class SetGitPathRequest {
-	    gitPath: string;
+	    static createFrom(source: any = {}) {
+	        return new ExportFastImportResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.streamPath = source["streamPath"];
+	        this.scriptPaths = source["scriptPaths"];
+	        this.commitCount = source["commitCount"];
+	    }
+	}
+	export class ExportRepoBundleRequest {
+	    repoPath: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new SetGitPathRequest(source);
+	        return new ExportRepoBundleRequest(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.gitPath = source["gitPath"];
+	        this.repoPath = source["repoPath"];
 	    }
 	}
-	export class SetGitPathResponse {
-	    success: boolean;
-	    message: string;
-	    version: string;
+	export class ExportRepoBundleResponse {
+	    bundlePath: string;
+	    size: number;
+	    refs: string[];
 	
 	    static createFrom(source: any = {}) {
-	        return new SetGitPathResponse(source);
+	        return new ExportRepoBundleResponse(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.success = source["success"];
-	        this.message = source["message"];
-	        this.version = source["version"];
+	        this.bundlePath = source["bundlePath"];
+	        this.size = source["size"];
+	        this.refs = source["refs"];
+	    }
+	}
+	export class GenerateRepoRequest {
+	    year: number;
+	    githubUsername: string;
+	    githubEmail: string;
+	    repoName: string;
+	    contributions: ContributionDay[];
+	    remoteRepo?: RemoteRepoOptions;
+	    timezone?: string;
+	    identity?: DesignIdentity;
+	    allowUnattributedEmail?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GenerateRepoRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.year = source["year"];
+	        this.githubUsername = source["githubUsername"];
+	        this.githubEmail = source["githubEmail"];
+	        this.repoName = source["repoName"];
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.remoteRepo = this.convertValues(source["remoteRepo"], RemoteRepoOptions);
+	        this.timezone = source["timezone"];
+	        this.identity = this.convertValues(source["identity"], DesignIdentity);
+	        this.allowUnattributedEmail = source["allowUnattributedEmail"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GenerateRepoResponse {
+	    repoPath: string;
+	    commitCount: number;
+	    remoteUrl?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GenerateRepoResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repoPath = source["repoPath"];
+	        this.commitCount = source["commitCount"];
+	        this.remoteUrl = source["remoteUrl"];
+	    }
+	}
+	export class GitHistoryAuthor {
+	    email: string;
+	    name: string;
+	    commits: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistoryAuthor(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.email = source["email"];
+	        this.name = source["name"];
+	        this.commits = source["commits"];
+	    }
+	}
+	export class GitHistoryImportRequest {
+	    repoPath?: string;
+	    authorEmails?: string[];
+	    branches?: string[];
+	    allBranches?: boolean;
+	    since?: string;
+	    until?: string;
+	    dateSource?: string;
+	    includeMerges?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistoryImportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repoPath = source["repoPath"];
+	        this.authorEmails = source["authorEmails"];
+	        this.branches = source["branches"];
+	        this.allBranches = source["allBranches"];
+	        this.since = source["since"];
+	        this.until = source["until"];
+	        this.dateSource = source["dateSource"];
+	        this.includeMerges = source["includeMerges"];
+	    }
+	}
+	export class GitHistoryImportResponse {
+	    repoPath: string;
+	    commitsRead: number;
+	    commitsKept: number;
+	    authors: GitHistoryAuthor[];
+	    contributions: ContributionDay[];
+	
+	    static createFrom(source: any = {}) {
+	        return new GitHistoryImportResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repoPath = source["repoPath"];
+	        this.commitsRead = source["commitsRead"];
+	        this.commitsKept = source["commitsKept"];
+	        this.authors = this.convertValues(source["authors"], GitHistoryAuthor);
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GithubUserProfile {
+	    id?: number;
+	    login: string;
+	    name: string;
+	    email: string;
+	    avatarUrl: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubUserProfile(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.login = source["login"];
+	        this.name = source["name"];
+	        this.email = source["email"];
+	        this.avatarUrl = source["avatarUrl"];
+	    }
+	}
+	export class GithubAccount {
+	    id: string;
+	    host: string;
+	    label: string;
+	    user?: GithubUserProfile;
+	    active: boolean;
+	    remembered: boolean;
+	    locked: boolean;
+	    // Go type: time
+	    expiresAt?: any;
+	    expired: boolean;
+	    unverified: boolean;
+	    unverifiedReason?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubAccount(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.host = source["host"];
+	        this.label = source["label"];
+	        this.user = this.convertValues(source["user"], GithubUserProfile);
+	        this.active = source["active"];
+	        this.remembered = source["remembered"];
+	        this.locked = source["locked"];
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	        this.expired = source["expired"];
+	        this.unverified = source["unverified"];
+	        this.unverifiedReason = source["unverifiedReason"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GithubAppAuthRequest {
+	    host?: string;
+	    appId: string;
+	    installationId: number;
+	    privateKeyPath?: string;
+	    remember: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubAppAuthRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.appId = source["appId"];
+	        this.installationId = source["installationId"];
+	        this.privateKeyPath = source["privateKeyPath"];
+	        this.remember = source["remember"];
+	    }
+	}
+	export class GithubAuthRequest {
+	    host?: string;
+	    token: string;
+	    remember: boolean;
+	    passphrase?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubAuthRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.host = source["host"];
+	        this.token = source["token"];
+	        this.remember = source["remember"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class GithubAuthResponse {
+	    accountId: string;
+	    user?: GithubUserProfile;
+	    remembered: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubAuthResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accountId = source["accountId"];
+	        this.user = this.convertValues(source["user"], GithubUserProfile);
+	        this.remembered = source["remembered"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class GithubEmail {
+	    email: string;
+	    primary: boolean;
+	    verified: boolean;
+	    visibility: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubEmail(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.email = source["email"];
+	        this.primary = source["primary"];
+	        this.verified = source["verified"];
+	        this.visibility = source["visibility"];
+	    }
+	}
+	export class GithubEmailList {
+	    emails: GithubEmail[];
+	    noReplyEmail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubEmailList(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.emails = this.convertValues(source["emails"], GithubEmail);
+	        this.noReplyEmail = source["noReplyEmail"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TokenPermissions {
+	    tokenType: string;
+	    scopes?: string[];
+	    canCreateRepo: boolean;
+	    canCreatePrivateRepo: boolean;
+	    canPush: boolean;
+	    canDeleteRepo: boolean;
+	    verified: boolean;
+	    note?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenPermissions(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.tokenType = source["tokenType"];
+	        this.scopes = source["scopes"];
+	        this.canCreateRepo = source["canCreateRepo"];
+	        this.canCreatePrivateRepo = source["canCreatePrivateRepo"];
+	        this.canPush = source["canPush"];
+	        this.canDeleteRepo = source["canDeleteRepo"];
+	        this.verified = source["verified"];
+	        this.note = source["note"];
+	    }
+	}
+	export class GithubLoginStatus {
+	    authenticated: boolean;
+	    accountId?: string;
+	    user?: GithubUserProfile;
+	    permissions?: TokenPermissions;
+	    // Go type: time
+	    expiresAt?: any;
+	    expired?: boolean;
+	    unverified?: boolean;
+	    unverifiedReason?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GithubLoginStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.authenticated = source["authenticated"];
+	        this.accountId = source["accountId"];
+	        this.user = this.convertValues(source["user"], GithubUserProfile);
+	        this.permissions = this.convertValues(source["permissions"], TokenPermissions);
+	        this.expiresAt = this.convertValues(source["expiresAt"], null);
+	        this.expired = source["expired"];
+	        this.unverified = source["unverified"];
+	        this.unverifiedReason = source["unverifiedReason"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class ImageImportRequest {
+	    filePath?: string;
+	    width?: number;
+	    height?: number;
+	    mode?: string;
+	    invert?: boolean;
+	    threshold?: number;
+	    nearest?: boolean;
+	    strokeRecovery?: number;
+	    secondaryRecovery?: number;
+	    dilation?: number;
+	    dither?: string;
+	    year?: number;
+	    column?: number;
+	    row?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageImportRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.mode = source["mode"];
+	        this.invert = source["invert"];
+	        this.threshold = source["threshold"];
+	        this.nearest = source["nearest"];
+	        this.strokeRecovery = source["strokeRecovery"];
+	        this.secondaryRecovery = source["secondaryRecovery"];
+	        this.dilation = source["dilation"];
+	        this.dither = source["dither"];
+	        this.year = source["year"];
+	        this.column = source["column"];
+	        this.row = source["row"];
+	    }
+	}
+	export class ImageImportResponse {
+	    filePath: string;
+	    width: number;
+	    height: number;
+	    grid: number[][];
+	    contributions: ContributionDay[];
+	    skippedCells: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImageImportResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	        this.grid = source["grid"];
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.skippedCells = source["skippedCells"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportContributionsRequest {
+	    filePath?: string;
+	    year?: number;
+	    duplicatePolicy?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportContributionsRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.year = source["year"];
+	        this.duplicatePolicy = source["duplicatePolicy"];
+	    }
+	}
+	export class ImportIssue {
+	    index: number;
+	    offset: number;
+	    line: number;
+	    column: number;
+	    date?: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportIssue(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.index = source["index"];
+	        this.offset = source["offset"];
+	        this.line = source["line"];
+	        this.column = source["column"];
+	        this.date = source["date"];
+	        this.message = source["message"];
+	    }
+	}
+	export class ImportReport {
+	    entries: number;
+	    accepted: number;
+	    warnings: ImportIssue[];
+	    fixes: ImportIssue[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.entries = source["entries"];
+	        this.accepted = source["accepted"];
+	        this.warnings = this.convertValues(source["warnings"], ImportIssue);
+	        this.fixes = this.convertValues(source["fixes"], ImportIssue);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ImportContributionsResponse {
+	    contributions: ContributionDay[];
+	    design?: DesignDocument;
+	    report?: ImportReport;
+	
+	    static createFrom(source: any = {}) {
+	        return new ImportContributionsResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.design = this.convertValues(source["design"], DesignDocument);
+	        this.report = this.convertValues(source["report"], ImportReport);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class ManagedRepo {
+	    fullName: string;
+	    owner: string;
+	    name: string;
+	    htmlUrl: string;
+	    private: boolean;
+	    archived: boolean;
+	    createdAt: string;
+	    pushedAt: string;
+	    year?: number;
+	    firstDate?: string;
+	    lastDate?: string;
+	    commitCount?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManagedRepo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.fullName = source["fullName"];
+	        this.owner = source["owner"];
+	        this.name = source["name"];
+	        this.htmlUrl = source["htmlUrl"];
+	        this.private = source["private"];
+	        this.archived = source["archived"];
+	        this.createdAt = source["createdAt"];
+	        this.pushedAt = source["pushedAt"];
+	        this.year = source["year"];
+	        this.firstDate = source["firstDate"];
+	        this.lastDate = source["lastDate"];
+	        this.commitCount = source["commitCount"];
+	    }
+	}
+	export class ManagedRepoActionRequest {
+	    action: string;
+	    repos: string[];
+	    private: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManagedRepoActionRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.action = source["action"];
+	        this.repos = source["repos"];
+	        this.private = source["private"];
+	    }
+	}
+	export class ManagedRepoActionResult {
+	    repo: string;
+	    success: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ManagedRepoActionResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.repo = source["repo"];
+	        this.success = source["success"];
+	        this.error = source["error"];
+	    }
+	}
+	export class NetworkSettings {
+	    proxyUrl?: string;
+	    noProxy?: string[];
+	    caCertFiles?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NetworkSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.proxyUrl = source["proxyUrl"];
+	        this.noProxy = source["noProxy"];
+	        this.caCertFiles = source["caCertFiles"];
+	    }
+	}
+	export class PendingGeneration {
+	    accountId?: string;
+	    repoPath: string;
+	    commitCount: number;
+	    owner: string;
+	    repoName: string;
+	    cloneUrl: string;
+	    htmlUrl?: string;
+	    branch: string;
+	    topics?: string[];
+	    archive?: boolean;
+	    steps: string[];
+	    lastError?: string;
+	    // Go type: time
+	    createdAt: any;
+	    // Go type: time
+	    updatedAt: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new PendingGeneration(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.accountId = source["accountId"];
+	        this.repoPath = source["repoPath"];
+	        this.commitCount = source["commitCount"];
+	        this.owner = source["owner"];
+	        this.repoName = source["repoName"];
+	        this.cloneUrl = source["cloneUrl"];
+	        this.htmlUrl = source["htmlUrl"];
+	        this.branch = source["branch"];
+	        this.topics = source["topics"];
+	        this.archive = source["archive"];
+	        this.steps = source["steps"];
+	        this.lastError = source["lastError"];
+	        this.createdAt = this.convertValues(source["createdAt"], null);
+	        this.updatedAt = this.convertValues(source["updatedAt"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	export class RenameGithubAccountRequest {
+	    id: string;
+	    label: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenameGithubAccountRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.label = source["label"];
+	    }
+	}
+	export class RenderCalendarRequest {
+	    contributions: ContributionDay[];
+	    year?: number;
+	    window?: DesignWindow;
+	    format?: string;
+	    theme?: string;
+	    customTheme?: CalendarTheme;
+	    scale?: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenderCalendarRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.contributions = this.convertValues(source["contributions"], ContributionDay);
+	        this.year = source["year"];
+	        this.window = this.convertValues(source["window"], DesignWindow);
+	        this.format = source["format"];
+	        this.theme = source["theme"];
+	        this.customTheme = this.convertValues(source["customTheme"], CalendarTheme);
+	        this.scale = source["scale"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RenderCalendarResponse {
+	    filePath: string;
+	    format: string;
+	    width: number;
+	    height: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RenderCalendarResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.filePath = source["filePath"];
+	        this.format = source["format"];
+	        this.width = source["width"];
+	        this.height = source["height"];
+	    }
+	}
+	export class RepoNameCheckRequest {
+	    template: string;
+	    year: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RepoNameCheckRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.template = source["template"];
+	        this.year = source["year"];
+	    }
+	}
+	export class RepoNameCheckResponse {
+	    name: string;
+	    available: boolean;
+	    suggestions?: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RepoNameCheckResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.available = source["available"];
+	        this.suggestions = source["suggestions"];
+	    }
+	}
+	export class SetGitPathRequest {
+	    gitPath: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetGitPathRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.gitPath = source["gitPath"];
+	    }
+	}
+	export class SetGitPathResponse {
+	    success: boolean;
+	    message: string;
+	    version: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new SetGitPathResponse(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.success = source["success"];
+	        this.message = source["message"];
+	        this.version = source["version"];
+	    }
+	}
+	
+	export class TokenStorageRequest {
+	    backend: string;
+	    passphrase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenStorageRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.backend = source["backend"];
+	        this.passphrase = source["passphrase"];
+	    }
+	}
+	export class TokenStorageStatus {
+	    keyringAvailable: boolean;
+	    backend?: string;
+	    locked: boolean;
+	    needsMigration: boolean;
+	    warning?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TokenStorageStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.keyringAvailable = source["keyringAvailable"];
+	        this.backend = source["backend"];
+	        this.locked = source["locked"];
+	        this.needsMigration = source["needsMigration"];
+	        this.warning = source["warning"];
+	    }
+	}
+	export class UnlockTokenRequest {
+	    passphrase: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new UnlockTokenRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.passphrase = source["passphrase"];
 	    }
 	}
 
@@ -0,0 +1,357 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Generation steps recorded in the pending-generation journal, in the order they complete.
+const (
+	generationStepLocalRepo       = "local-repository"
+	generationStepHistory         = "history"
+	generationStepRemoteRequested = "remote-repository-requested" // journaled before the creation request is sent
+	generationStepRemoteRepo      = "remote-repository"
+	generationStepTagged          = "tagged" // after remote-repository; pushing doesn't wait for it
+	generationStepPushed          = "pushed"
+)
+
+// errGenerationPending marks failures that left a created remote repository behind.
+var errGenerationPending = errors.New("generation is pending")
+
+// errGithubRepoNotFound is a 404 for a repository, which GitHub also answers when the token
+// can't see it.
+var errGithubRepoNotFound = errors.New("not found or not accessible")
+
+// PendingGeneration is the journal of a generation whose remote repository was
+// requested or created but whose history has not been pushed yet. It survives restarts so the
+// user can either resume the push or roll the whole generation back.
+type PendingGeneration struct {
+	AccountID   string    `json:"accountId,omitempty"` // the account that created the repository; empty in older journals
+	RepoPath    string    `json:"repoPath"`
+	CommitCount int       `json:"commitCount"`
+	Owner       string    `json:"owner"`
+	RepoName    string    `json:"repoName"`
+	CloneURL    string    `json:"cloneUrl"`
+	HTMLURL     string    `json:"htmlUrl,omitempty"`
+	Branch      string    `json:"branch"`
+	Topics      []string  `json:"topics,omitempty"`  // greenwall included; the repository can't be managed until tagged
+	Archive     bool      `json:"archive,omitempty"` // archive the repository once the push succeeds
+	Steps       []string  `json:"steps"`
+	LastError   string    `json:"lastError,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+func (p *PendingGeneration) recordStep(step string) {
+	if containsString(p.Steps, step) {
+		return
+	}
+	p.Steps = append(p.Steps, step)
+}
+
+// GetPendingGeneration returns the generation awaiting a resume or discard, or nil if there is none.
+func (a *App) GetPendingGeneration() (*PendingGeneration, error) {
+	return a.loadPendingGeneration()
+}
+
+// ResumePendingGeneration pushes the journaled local history into the already-created remote repository.
+func (a *App) ResumePendingGeneration() (*GenerateRepoResponse, error) {
+	pending, err := a.loadPendingGeneration()
+	if err != nil {
+		return nil, err
+	}
+	if pending == nil {
+		return nil, fmt.Errorf("no pending generation to resume")
+	}
+	auth, err := a.accountGithubAuth(pending.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	if auth.Token == "" {
+		return nil, fmt.Errorf("GitHub login is required to resume the push")
+	}
+	if _, err := os.Stat(pending.RepoPath); err != nil {
+		return nil, fmt.Errorf("local repository %s is no longer available; discard the pending generation instead", pending.RepoPath)
+	}
+	if pending.CloneURL == "" {
+		return nil, fmt.Errorf("the address of %s/%s was not recorded; discard the pending generation instead", pending.Owner, pending.RepoName)
+	}
+
+	remoteURL, err := a.pushPendingGeneration(pending, auth)
+	if err != nil {
+		return nil, err
+	}
+	if remoteURL != "" && a.ctx != nil {
+		runtime.BrowserOpenURL(a.ctx, remoteURL)
+	}
+
+	return &GenerateRepoResponse{
+		RepoPath:    pending.RepoPath,
+		CommitCount: pending.CommitCount,
+		RemoteURL:   remoteURL,
+	}, nil
+}
+
+// DiscardPendingGeneration rolls a pending generation back by deleting the created
+// remote repository, with the token of the account that created it, and the local
+// working directory. Deleting the repository is confirmed in a native dialog first.
+func (a *App) DiscardPendingGeneration() error {
+	pending, err := a.loadPendingGeneration()
+	if err != nil {
+		return err
+	}
+	if pending == nil {
+		return nil
+	}
+
+	if containsString(pending.Steps, generationStepRemoteRepo) || containsString(pending.Steps, generationStepRemoteRequested) {
+		auth, err := a.accountGithubAuth(pending.AccountID)
+		if err != nil {
+			return err
+		}
+		if auth.Token == "" {
+			return fmt.Errorf("GitHub login is required to delete %s/%s", pending.Owner, pending.RepoName)
+		}
+		confirmed, err := a.confirmDiscardPendingGeneration(pending)
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			return fmt.Errorf("discard cancelled")
+		}
+		err = a.deletePendingRepository(auth, pending)
+		a.auditAs(auth.AccountID, auditActionRepoDelete, pending.Owner+"/"+pending.RepoName, err, map[string]string{"reason": "discard pending generation"})
+		if err != nil {
+			return err
+		}
+	}
+	if pending.RepoPath != "" {
+		if err := os.RemoveAll(pending.RepoPath); err != nil {
+			return fmt.Errorf("remove local repository: %w", err)
+		}
+	}
+	return a.clearPendingGeneration()
+}
+
+// deletePendingRepository deletes the journaled repository. A requested repository may
+// not have been created, so a 404 is accepted once a lookup with the same token agrees
+// that it does not exist.
+func (a *App) deletePendingRepository(auth githubAuth, pending *PendingGeneration) error {
+	err := a.deleteGithubRepository(auth, pending.Owner, pending.RepoName)
+	if !errors.Is(err, errGithubRepoNotFound) {
+		return err
+	}
+	exists, lookupErr := a.githubRepositoryExists(auth, pending.Owner, pending.RepoName)
+	switch {
+	case lookupErr != nil:
+		return fmt.Errorf("%v; checking whether it still exists failed: %w", err, lookupErr)
+	case exists:
+		return fmt.Errorf("%s/%s still exists but could not be deleted with the token of %s", pending.Owner, pending.RepoName, auth.AccountID)
+	}
+	return nil
+}
+
+func (a *App) confirmDiscardPendingGeneration(pending *PendingGeneration) (bool, error) {
+	if a.ctx == nil {
+		return false, fmt.Errorf("discarding %s/%s requires confirmation", pending.Owner, pending.RepoName)
+	}
+	choice, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:          runtime.QuestionDialog,
+		Title:         "放弃未完成的生成",
+		Message:       fmt.Sprintf("确定要永久删除远程仓库 %s/%s 和本地目录 %s 吗？", pending.Owner, pending.RepoName, pending.RepoPath),
+		Buttons:       []string{"Yes", "No"},
+		DefaultButton: "No",
+		CancelButton:  "No",
+	})
+	if err != nil {
+		return false, fmt.Errorf("open confirmation dialog: %w", err)
+	}
+	return choice == "Yes", nil
+}
+
+// pushPendingGeneration tags the repository and pushes the journaled history with auth,
+// keeping the journal up to date so a failure of either can be resumed later.
+func (a *App) pushPendingGeneration(pending *PendingGeneration, auth githubAuth) (string, error) {
+	owner := pending.Owner
+	if user := a.session().User; owner == "" && user != nil {
+		owner = user.Login
+	}
+
+	branch := pending.Branch
+	if branch == "" {
+		branch = defaultBranchName
+	}
+
+	repo := pending.Owner + "/" + pending.RepoName
+
+	var tagErr error
+	if len(pending.Topics) > 0 && !containsString(pending.Steps, generationStepTagged) {
+		if tagErr = a.setGithubRepositoryTopics(auth, pending.Owner, pending.RepoName, pending.Topics); tagErr == nil {
+			pending.recordStep(generationStepTagged)
+		}
+	}
+
+	if !containsString(pending.Steps, generationStepPushed) {
+		username, token := gitCredentials(auth, owner)
+		err := a.configureRemoteAndPush(pending.RepoPath, pending.CloneURL, branch, username, token)
+		a.auditAs(auth.AccountID, auditActionRepoPush, repo, err, map[string]string{"branch": branch, "commits": fmt.Sprint(pending.CommitCount)})
+		if err != nil {
+			a.recordPendingError(pending, err)
+			return "", fmt.Errorf("%w: push to %s/%s failed, the remote repository was kept so the push can be resumed or discarded: %v", errGenerationPending, pending.Owner, pending.RepoName, err)
+		}
+		pending.recordStep(generationStepPushed)
+	}
+	// Only tagged repositories can be managed later, and an archived one can't be tagged,
+	// so a failure stays pending until a resume succeeds.
+	if tagErr != nil {
+		a.recordPendingError(pending, tagErr)
+		return "", fmt.Errorf("%w: %s was pushed but tagging it with the %s topic failed; resume to try again: %v", errGenerationPending, repo, greenWallTopic, tagErr)
+	}
+
+	if pending.Archive {
+		err := a.updateGithubRepository(auth, pending.Owner, pending.RepoName, map[string]interface{}{"archived": true})
+		a.auditAs(auth.AccountID, auditActionRepoArchive, repo, err, nil)
+		if err != nil && a.ctx != nil {
+			runtime.LogWarningf(a.ctx, "failed to archive %s/%s: %v", pending.Owner, pending.RepoName, err)
+		}
+	}
+	if err := a.clearPendingGeneration(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to clear pending generation: %v", err)
+	}
+
+	if pending.HTMLURL != "" {
+		return pending.HTMLURL, nil
+	}
+	return pending.CloneURL, nil
+}
+
+// recordPendingError journals why the last attempt failed.
+func (a *App) recordPendingError(pending *PendingGeneration, err error) {
+	pending.LastError = err.Error()
+	pending.UpdatedAt = time.Now()
+	if saveErr := a.savePendingGeneration(pending); saveErr != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to record pending generation: %v", saveErr)
+	}
+}
+
+func (a *App) deleteGithubRepository(auth githubAuth, owner, name string) error {
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(name))
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodDelete, path, auth.Token, nil)
+	if err != nil {
+		return fmt.Errorf("build GitHub repository deletion request failed: %w", err)
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return fmt.Errorf("delete GitHub repository failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%s/%s was %w", owner, name, errGithubRepoNotFound)
+	case resp.StatusCode == http.StatusForbidden:
+		return fmt.Errorf("token is not allowed to delete %s/%s (the delete_repo scope is required)", owner, name)
+	case resp.StatusCode >= 400:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned error for repository deletion (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func pendingGenerationPath() (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "pending_generation.json"), nil
+}
+
+func (a *App) loadPendingGeneration() (*PendingGeneration, error) {
+	path, err := pendingGenerationPath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read pending generation: %w", err)
+	}
+
+	var pending PendingGeneration
+	if err := json.Unmarshal(data, &pending); err != nil {
+		return nil, fmt.Errorf("decode pending generation: %w", err)
+	}
+	return &pending, nil
+}
+
+func (a *App) savePendingGeneration(pending *PendingGeneration) error {
+	path, err := pendingGenerationPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(pending, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode pending generation: %w", err)
+	}
+	return writeFileAtomically(path, data, 0o600)
+}
+
+// writeFileAtomically replaces path through a temporary file in the same directory, so a
+// crash leaves either the old or the new contents, never a truncated file.
+func writeFileAtomically(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // fails harmlessly once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+func (a *App) clearPendingGeneration() error {
+	path, err := pendingGenerationPath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDeletePendingRepositoryUsesTheCreatingAccount(t *testing.T) {
+	tests := []struct {
+		name         string
+		deleteStatus int
+		lookupStatus int
+		wantErr      string
+	}{
+		{name: "deleted", deleteStatus: http.StatusNoContent},
+		{name: "never created", deleteStatus: http.StatusNotFound, lookupStatus: http.StatusNotFound},
+		{name: "not accessible", deleteStatus: http.StatusNotFound, lookupStatus: http.StatusOK, wantErr: "still exists"},
+		{name: "lookup failed", deleteStatus: http.StatusNotFound, lookupStatus: http.StatusBadGateway, wantErr: "not found or not accessible"},
+		{name: "forbidden", deleteStatus: http.StatusForbidden, wantErr: "delete_repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if got := r.Header.Get("Authorization"); got != "Bearer gho_creator" {
+					t.Errorf("%s %s with %q, want the creating account's token", r.Method, r.URL.Path, got)
+				}
+				if r.URL.Path != "/repos/octocat/wall" {
+					t.Errorf("path = %s", r.URL.Path)
+				}
+				if r.Method == http.MethodDelete {
+					w.WriteHeader(tt.deleteStatus)
+					return
+				}
+				w.WriteHeader(tt.lookupStatus)
+			}))
+			creator := &githubAccount{ID: "github.com/octocat", Host: defaultGithubHost, User: &GithubUserProfile{Login: "octocat"}, token: "gho_creator"}
+			other := &githubAccount{ID: "github.com/hubot", Host: defaultGithubHost, User: &GithubUserProfile{Login: "hubot"}, token: "gho_other"}
+			app.accounts = []*githubAccount{creator, other}
+			app.activeAccountID = other.ID
+
+			pending := &PendingGeneration{AccountID: creator.ID, Owner: "octocat", RepoName: "wall"}
+			auth, err := app.accountGithubAuth(pending.AccountID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = app.deletePendingRepository(auth, pending)
+			if tt.wantErr == "" && err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAccountGithubAuthForAnAccountThatIsGone(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	if _, err := app.accountGithubAuth("github.com/octocat"); err == nil {
+		t.Fatal("an account that is no longer signed in was accepted")
+	}
+	auth, err := app.accountGithubAuth("")
+	if err != nil || auth.Token != "" {
+		t.Fatalf("without an active account: %+v, %v", auth, err)
+	}
+}
+
+func TestDiscardPendingGenerationNeedsConfirmation(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected %s %s before confirmation", r.Method, r.URL.Path)
+	}))
+	acc := &githubAccount{ID: "github.com/octocat", Host: defaultGithubHost, User: &GithubUserProfile{Login: "octocat"}, token: "gho_creator"}
+	app.accounts = []*githubAccount{acc}
+	app.activeAccountID = acc.ID
+
+	repoPath := t.TempDir()
+	pending := &PendingGeneration{
+		AccountID: acc.ID,
+		RepoPath:  repoPath,
+		Owner:     "octocat",
+		RepoName:  "wall",
+		Steps:     []string{generationStepLocalRepo, generationStepHistory, generationStepRemoteRequested, generationStepRemoteRepo},
+	}
+	if err := app.savePendingGeneration(pending); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a window to ask in, nothing is deleted and the journal is kept.
+	if err := app.DiscardPendingGeneration(); err == nil || !strings.Contains(err.Error(), "requires confirmation") {
+		t.Fatalf("error = %v, want a request for confirmation", err)
+	}
+	if _, err := os.Stat(repoPath); err != nil {
+		t.Errorf("the local repository was removed: %v", err)
+	}
+	if kept, err := app.loadPendingGeneration(); err != nil || kept == nil {
+		t.Errorf("journal = %v, %v, want it kept", kept, err)
+	}
+}
+
+func TestSavePendingGenerationReplacesTheJournal(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	first := &PendingGeneration{AccountID: "github.com/octocat", Owner: "octocat", RepoName: "wall", Steps: []string{generationStepRemoteRequested}}
+	second := &PendingGeneration{AccountID: "github.com/octocat", Owner: "octocat", RepoName: "wall", Steps: []string{generationStepRemoteRequested, generationStepRemoteRepo}}
+	for _, pending := range []*PendingGeneration{first, second} {
+		if err := app.savePendingGeneration(pending); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	got, err := app.loadPendingGeneration()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, second) {
+		t.Errorf("journal = %+v, want %+v", got, second)
+	}
+	path, _ := pendingGenerationPath()
+	leftovers, _ := filepath.Glob(path + ".*.tmp")
+	if len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("journal mode = %v, %v, want 0600", info, err)
+	}
+}
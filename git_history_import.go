@@ -0,0 +1,226 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	gitHistoryDateAuthor    = "author"    // when the change was written; what GitHub counts
+	gitHistoryDateCommitter = "committer" // when it was last committed, e.g. by a rebase
+)
+
+// gitHistoryFieldSep separates the fields of one commit in the git log output.
+const gitHistoryFieldSep = "\x1f"
+
+type GitHistoryImportRequest struct {
+	RepoPath      string   `json:"repoPath,omitempty"`      // empty asks with a directory dialog
+	AuthorEmails  []string `json:"authorEmails,omitempty"`  // case-insensitive; empty keeps every author
+	Branches      []string `json:"branches,omitempty"`      // empty reads HEAD
+	AllBranches   bool     `json:"allBranches,omitempty"`   // read every local and remote-tracking branch
+	Since         string   `json:"since,omitempty"`         // YYYY-MM-DD, inclusive
+	Until         string   `json:"until,omitempty"`         // YYYY-MM-DD, inclusive
+	DateSource    string   `json:"dateSource,omitempty"`    // author (default) or committer
+	IncludeMerges bool     `json:"includeMerges,omitempty"` // GitHub counts merge commits too
+}
+
+// GitHistoryAuthor summarises one author email so the emails to keep can be picked.
+type GitHistoryAuthor struct {
+	Email   string `json:"email"`
+	Name    string `json:"name"`
+	Commits int    `json:"commits"`
+}
+
+type GitHistoryImportResponse struct {
+	RepoPath      string             `json:"repoPath"`
+	CommitsRead   int                `json:"commitsRead"`
+	CommitsKept   int                `json:"commitsKept"`
+	Authors       []GitHistoryAuthor `json:"authors"` // every author in the range, most commits first
+	Contributions []ContributionDay  `json:"contributions"`
+}
+
+// ImportGitHistory builds contributions from the commits of a local repository.
+func (a *App) ImportGitHistory(req GitHistoryImportRequest) (*GitHistoryImportResponse, error) {
+	repoPath := strings.TrimSpace(req.RepoPath)
+	if repoPath == "" {
+		var err error
+		repoPath, err = runtime.OpenDirectoryDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "选择 Git 仓库",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open directory dialog: %w", err)
+		}
+		if repoPath == "" {
+			return nil, fmt.Errorf("import cancelled")
+		}
+	}
+	if info, err := os.Stat(repoPath); err != nil || !info.IsDir() {
+		return nil, fmt.Errorf("%s is not a directory", repoPath)
+	}
+
+	resp, err := a.readGitHistory(repoPath, req)
+	if err != nil {
+		return nil, err
+	}
+	resp.RepoPath = repoPath
+	return resp, nil
+}
+
+func (a *App) readGitHistory(repoPath string, req GitHistoryImportRequest) (*GitHistoryImportResponse, error) {
+	since, err := parseTimeBound(req.Since, false)
+	if err != nil {
+		return nil, err
+	}
+	until, err := parseTimeBound(req.Until, true)
+	if err != nil {
+		return nil, err
+	}
+	sinceDay, untilDay := "", ""
+	if !since.IsZero() {
+		sinceDay = since.Format("2006-01-02")
+	}
+	if !until.IsZero() {
+		untilDay = until.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+	if sinceDay != "" && untilDay != "" && sinceDay > untilDay {
+		return nil, fmt.Errorf("the start date %s is after the end date %s", sinceDay, untilDay)
+	}
+
+	dateField := "%aI"
+	switch strings.ToLower(strings.TrimSpace(req.DateSource)) {
+	case "", gitHistoryDateAuthor:
+	case gitHistoryDateCommitter:
+		dateField = "%cI"
+	default:
+		return nil, fmt.Errorf("unsupported date source %q (use author or committer)", req.DateSource)
+	}
+
+	args := []string{"log", "--format=" + strings.Join([]string{dateField, "%aE", "%aN"}, gitHistoryFieldSep)}
+	// git compares commit times as instants, while days are taken in each commit's own time
+	// zone, so the bounds handed to git are a day wider and the exact days are checked below.
+	// --until only applies to committer dates: rebased commits are committed after they were
+	// written, and git would leave out those written in the range.
+	if !since.IsZero() {
+		args = append(args, "--since="+since.AddDate(0, 0, -1).Format(time.RFC3339))
+	}
+	if !until.IsZero() && dateField == "%cI" {
+		args = append(args, "--until="+until.AddDate(0, 0, 1).Format(time.RFC3339))
+	}
+	if !req.IncludeMerges {
+		args = append(args, "--no-merges")
+	}
+	if req.AllBranches {
+		args = append(args, "--branches", "--remotes")
+	}
+	for _, branch := range req.Branches {
+		branch = strings.TrimSpace(branch)
+		if branch == "" {
+			continue
+		}
+		// Anything starting with a dash would be read as an option.
+		if strings.HasPrefix(branch, "-") {
+			return nil, fmt.Errorf("invalid branch name %q", branch)
+		}
+		args = append(args, branch)
+	}
+	args = append(args, "--")
+
+	emails := make(map[string]bool, len(req.AuthorEmails))
+	for _, email := range req.AuthorEmails {
+		if email = strings.ToLower(strings.TrimSpace(email)); email != "" {
+			emails[email] = true
+		}
+	}
+
+	resp := &GitHistoryImportResponse{Authors: []GitHistoryAuthor{}, Contributions: []ContributionDay{}}
+	authors := map[string]*GitHistoryAuthor{}
+	counts := map[string]int{}
+	err = a.scanGitOutput(repoPath, args, func(line string) {
+		fields := strings.Split(line, gitHistoryFieldSep)
+		if len(fields) != 3 || len(fields[0]) < len("2006-01-02") {
+			return
+		}
+		resp.CommitsRead++
+		// The date is taken in the commit's own time zone, the day its author saw.
+		day := fields[0][:len("2006-01-02")]
+		if sinceDay != "" && day < sinceDay || untilDay != "" && day > untilDay {
+			return
+		}
+
+		email := strings.ToLower(fields[1])
+		author := authors[email]
+		if author == nil {
+			author = &GitHistoryAuthor{Email: fields[1], Name: fields[2]}
+			authors[email] = author
+		}
+		author.Commits++
+
+		if len(emails) > 0 && !emails[email] {
+			return
+		}
+		resp.CommitsKept++
+		counts[day]++
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, author := range authors {
+		resp.Authors = append(resp.Authors, *author)
+	}
+	sort.Slice(resp.Authors, func(i, j int) bool {
+		if resp.Authors[i].Commits != resp.Authors[j].Commits {
+			return resp.Authors[i].Commits > resp.Authors[j].Commits
+		}
+		return resp.Authors[i].Email < resp.Authors[j].Email
+	})
+	for day, count := range counts {
+		resp.Contributions = append(resp.Contributions, ContributionDay{Date: day, Count: count})
+	}
+	sort.Slice(resp.Contributions, func(i, j int) bool { return resp.Contributions[i].Date < resp.Contributions[j].Date })
+	return resp, nil
+}
+
+// scanGitOutput runs git in dir and hands each line of its output to handle as it arrives,
+// so long histories are never held in memory at once.
+func (a *App) scanGitOutput(dir string, args []string, handle func(line string)) error {
+	cmd := a.gitCommand(dir, args...)
+
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		handle(scanner.Text())
+	}
+	scanErr := scanner.Err()
+	// Keep git from blocking on a full pipe if scanning stopped early.
+	io.Copy(io.Discard, stdout)
+	if err := cmd.Wait(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if strings.Contains(message, "does not have any commits yet") {
+			return fmt.Errorf("the repository has no commits yet")
+		}
+		return fmt.Errorf("git %s: %w (%s)", args[0], err, message)
+	}
+	if scanErr != nil {
+		return fmt.Errorf("read git %s output: %w", args[0], scanErr)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// gitHistoryFixture creates a repository with one empty commit per author and committer date.
+func gitHistoryFixture(t *testing.T, dates [][2]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	run := func(env []string, args ...string) {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run(nil, "init", "-q")
+	for _, date := range dates {
+		run([]string{"GIT_AUTHOR_DATE=" + date[0], "GIT_COMMITTER_DATE=" + date[1]},
+			"-c", "user.name=Octo Cat", "-c", "user.email=octocat@example.com", "commit", "-q", "--allow-empty", "-m", "commit")
+	}
+	return dir
+}
+
+func TestReadGitHistoryDateRange(t *testing.T) {
+	dir := gitHistoryFixture(t, [][2]string{
+		{"2023-06-01T12:00:00Z", "2023-06-01T12:00:00Z"},
+		{"2023-12-31T23:00:00+00:00", "2023-12-31T23:00:00+00:00"},
+		{"2024-01-01T00:30:00+09:00", "2024-01-01T00:30:00+09:00"}, // still 2023 in UTC
+		{"2024-01-10T10:00:00Z", "2024-03-01T10:00:00Z"},           // rebased long after
+		{"2024-01-31T23:30:00-05:00", "2024-01-31T23:30:00-05:00"}, // already February in UTC
+		{"2024-02-02T12:00:00Z", "2024-02-02T12:00:00Z"},
+	})
+
+	tests := []struct {
+		name       string
+		dateSource string
+		wantRead   int
+		want       []ContributionDay
+	}{
+		{
+			name:     "author dates",
+			wantRead: 5,
+			want: []ContributionDay{
+				{Date: "2024-01-01", Count: 1},
+				{Date: "2024-01-10", Count: 1},
+				{Date: "2024-01-31", Count: 1},
+			},
+		},
+		{
+			name:       "committer dates",
+			dateSource: gitHistoryDateCommitter,
+			wantRead:   3,
+			want: []ContributionDay{
+				{Date: "2024-01-01", Count: 1},
+				{Date: "2024-01-31", Count: 1},
+			},
+		},
+	}
+	app := NewApp()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.readGitHistory(dir, GitHistoryImportRequest{Since: "2024-01-01", Until: "2024-01-31", DateSource: tt.dateSource})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(resp.Contributions, tt.want) {
+				t.Errorf("contributions = %v, want %v", resp.Contributions, tt.want)
+			}
+			// git leaves out what is clearly outside the range before it reaches us.
+			if resp.CommitsRead != tt.wantRead {
+				t.Errorf("read %d commits, want %d", resp.CommitsRead, tt.wantRead)
+			}
+		})
+	}
+
+	if _, err := app.readGitHistory(dir, GitHistoryImportRequest{Since: "2024-02-01", Until: "2024-01-01"}); err == nil {
+		t.Error("a range ending before it starts was accepted")
+	}
+}
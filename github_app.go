@@ -0,0 +1,369 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// installationTokenUser is the user name git expects alongside an installation token.
+	installationTokenUser = "x-access-token"
+	// installationTokenRefreshMargin renews installation tokens (valid for an hour) this
+	// long before they run out, so a push never starts with a token about to expire.
+	installationTokenRefreshMargin = 5 * time.Minute
+	// appJWTLifetime stays under the ten minutes GitHub accepts; iat is backdated a minute
+	// to allow for clock drift.
+	appJWTLifetime = 9 * time.Minute
+)
+
+// githubAppCredentials identify a GitHub App installation. The private key stays in its
+// file and is read whenever a new installation token is needed.
+type githubAppCredentials struct {
+	AppID          string `json:"appId"`
+	InstallationID int64  `json:"installationId"`
+	PrivateKeyPath string `json:"privateKeyPath"`
+	// OwnerType is the type of the account the app is installed on: "Organization" or "User".
+	OwnerType string `json:"ownerType,omitempty"`
+}
+
+type GithubAppAuthRequest struct {
+	Host           string `json:"host,omitempty"` // GitHub Enterprise Server host; empty means github.com
+	AppID          string `json:"appId"`          // numeric app ID or the app's client ID
+	InstallationID int64  `json:"installationId"`
+	PrivateKeyPath string `json:"privateKeyPath,omitempty"` // empty asks with a file dialog
+	Remember       bool   `json:"remember"`
+}
+
+type installationTokenResponse struct {
+	Token       string            `json:"token"`
+	ExpiresAt   time.Time         `json:"expires_at"`
+	Permissions map[string]string `json:"permissions"`
+}
+
+type githubInstallation struct {
+	ID      int64  `json:"id"`
+	AppSlug string `json:"app_slug"`
+	Account struct {
+		Login     string `json:"login"`
+		Type      string `json:"type"`
+		AvatarURL string `json:"avatar_url"`
+	} `json:"account"`
+}
+
+// AuthenticateWithGithubApp signs in as a GitHub App installation. Repositories are then
+// created in the organisation the app is installed on and pushed with installation tokens,
+// which are renewed automatically.
+func (a *App) AuthenticateWithGithubApp(req GithubAppAuthRequest) (*GithubAuthResponse, error) {
+	host, err := normaliseGithubHost(req.Host)
+	if err != nil {
+		return nil, err
+	}
+	appID := strings.TrimSpace(req.AppID)
+	if appID == "" {
+		return nil, fmt.Errorf("GitHub App ID cannot be empty")
+	}
+	if req.InstallationID <= 0 {
+		return nil, fmt.Errorf("GitHub App installation ID is required")
+	}
+
+	keyPath := strings.TrimSpace(req.PrivateKeyPath)
+	if keyPath == "" && a.ctx != nil {
+		keyPath, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "选择 GitHub App 私钥",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "私钥文件 (*.pem)", Pattern: "*.pem"},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open file dialog: %w", err)
+		}
+	}
+	if keyPath == "" {
+		return nil, fmt.Errorf("GitHub App private key file is required")
+	}
+	if _, err := loadAppPrivateKey(keyPath); err != nil {
+		return nil, err
+	}
+
+	creds := &githubAppCredentials{AppID: appID, InstallationID: req.InstallationID, PrivateKeyPath: keyPath}
+	installation, err := a.fetchInstallation(host, creds)
+	if err != nil {
+		a.auditAs("", auditActionLogin, "", err, map[string]string{"host": host, "app": appID})
+		return nil, err
+	}
+	creds.OwnerType = installation.Account.Type
+
+	id := fmt.Sprintf("%s/installation/%d", host, req.InstallationID)
+	user := &GithubUserProfile{
+		Login:     installation.Account.Login,
+		Name:      installation.AppSlug + "[bot]",
+		AvatarURL: installation.Account.AvatarURL,
+	}
+	a.mu.Lock()
+	acc := a.findAccount(id)
+	if acc == nil {
+		acc = &githubAccount{ID: id, Host: host}
+		a.accounts = append(a.accounts, acc)
+	}
+	acc.App = creds
+	acc.User = user
+	acc.token = ""
+	acc.Expired = false
+	acc.unverified = ""
+	acc.avatarData = ""
+	acc.remembered = req.Remember
+	a.mu.Unlock()
+	if err := a.refreshInstallationToken(acc); err != nil {
+		a.auditAs(acc.ID, auditActionLogin, "", err, map[string]string{"host": host, "app": appID})
+		return nil, err
+	}
+	a.activateAccount(acc)
+	a.audit(auditActionLogin, "", nil, map[string]string{"host": host, "app": appID})
+	go a.cacheAvatar(acc.ID, user.AvatarURL)
+
+	if err := a.saveAccounts(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+	}
+	return &GithubAuthResponse{
+		AccountID:  acc.ID,
+		User:       cloneGithubUser(user),
+		Remembered: req.Remember,
+	}, nil
+}
+
+func (acc *githubAccount) isApp() bool {
+	return acc.App != nil
+}
+
+// githubAuth is the host and token of one account, looked up once so a series of calls
+// keeps to that account even if the user switches accounts in the meantime.
+type githubAuth struct {
+	AccountID string
+	Host      string
+	Token     string
+	App       bool // an installation token, which git pushes as installationTokenUser
+}
+
+// activeGithubToken returns the active account's token, renewing it first when it is an
+// installation token close to expiry.
+func (a *App) activeGithubToken() (string, error) {
+	auth, err := a.accountGithubAuth("")
+	return auth.Token, err
+}
+
+// accountGithubAuth returns the account's host and token, renewing an installation token
+// close to expiry first. An empty id is the active account, which may be nobody.
+func (a *App) accountGithubAuth(id string) (githubAuth, error) {
+	a.mu.Lock()
+	acc := a.activeAccount()
+	if id != "" {
+		acc = a.findAccount(id)
+	}
+	if acc == nil {
+		a.mu.Unlock()
+		if id != "" {
+			return githubAuth{}, fmt.Errorf("the GitHub account %s is no longer signed in; sign in with it again", id)
+		}
+		return githubAuth{Host: defaultGithubHost}, nil
+	}
+	auth := githubAuth{AccountID: acc.ID, Host: acc.Host, Token: acc.token, App: acc.isApp()}
+	fresh := !auth.App || (auth.Token != "" && time.Until(acc.installationExpiry) > installationTokenRefreshMargin)
+	a.mu.Unlock()
+	if fresh {
+		return auth, nil
+	}
+
+	if err := a.refreshInstallationToken(acc); err != nil {
+		return githubAuth{}, err
+	}
+	a.mu.Lock()
+	auth.Token = acc.token
+	a.mu.Unlock()
+	return auth, nil
+}
+
+// gitCredentials returns the user name and token git pushes with for auth.
+func gitCredentials(auth githubAuth, username string) (string, string) {
+	if auth.App {
+		username = installationTokenUser
+	}
+	return username, auth.Token
+}
+
+// refreshInstallationToken exchanges a freshly signed app JWT for an installation token.
+func (a *App) refreshInstallationToken(acc *githubAccount) error {
+	a.mu.Lock()
+	creds := *acc.App
+	a.mu.Unlock()
+
+	jwt, err := signAppJWT(&creds, time.Now())
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/app/installations/%d/access_tokens", creds.InstallationID)
+	req, err := a.newGithubRequestForHost(acc.Host, http.MethodPost, path, jwt, nil)
+	if err != nil {
+		return fmt.Errorf("build installation token request failed: %w", err)
+	}
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return fmt.Errorf("request installation token failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("%w: GitHub rejected the app's JWT; check the app ID and private key", errTokenRejected)
+	}
+	if resp.StatusCode >= 500 {
+		return fmt.Errorf("%w: GitHub API returned %d for the installation token", errGithubUnavailable, resp.StatusCode)
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned error for installation token (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload installationTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("decode installation token response failed: %w", err)
+	}
+	if payload.Token == "" {
+		return fmt.Errorf("GitHub did not return an installation token")
+	}
+
+	a.mu.Lock()
+	acc.token = payload.Token
+	acc.installationExpiry = payload.ExpiresAt
+	acc.permissions = installationPermissions(payload.Permissions)
+	a.mu.Unlock()
+	return nil
+}
+
+func (a *App) fetchInstallation(host string, creds *githubAppCredentials) (*githubInstallation, error) {
+	jwt, err := signAppJWT(creds, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/app/installations/%d", creds.InstallationID)
+	req, err := a.newGithubRequestForHost(host, http.MethodGet, path, jwt, nil)
+	if err != nil {
+		return nil, fmt.Errorf("build installation request failed: %w", err)
+	}
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch GitHub App installation failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, fmt.Errorf("%w: GitHub rejected the app's JWT; check the app ID and private key", errTokenRejected)
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GitHub API returned error for installation %d (%d): %s", creds.InstallationID, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var installation githubInstallation
+	if err := json.NewDecoder(resp.Body).Decode(&installation); err != nil {
+		return nil, fmt.Errorf("decode installation payload failed: %w", err)
+	}
+	return &installation, nil
+}
+
+// installationPermissions maps the permissions granted to an installation token.
+func installationPermissions(granted map[string]string) *TokenPermissions {
+	admin := granted["administration"] == "write"
+	permissions := &TokenPermissions{
+		TokenType:            tokenTypeInstallation,
+		CanCreateRepo:        admin,
+		CanCreatePrivateRepo: admin,
+		CanDeleteRepo:        admin,
+		CanPush:              granted["contents"] == "write",
+		Verified:             true,
+	}
+	for name, level := range granted {
+		permissions.Scopes = append(permissions.Scopes, name+":"+level)
+	}
+	return permissions
+}
+
+// signAppJWT builds the RS256 JWT a GitHub App authenticates with.
+func signAppJWT(creds *githubAppCredentials, now time.Time) (string, error) {
+	key, err := loadAppPrivateKey(creds.PrivateKeyPath)
+	if err != nil {
+		return "", err
+	}
+
+	// Numeric app IDs are sent as numbers; client IDs ("Iv1.…") as strings.
+	var issuer interface{} = creds.AppID
+	if id, err := strconv.ParseInt(creds.AppID, 10, 64); err == nil {
+		issuer = id
+	}
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": issuer,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign GitHub App JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// loadAppPrivateKey reads the PEM key downloaded from the app settings (PKCS#1), or a
+// PKCS#8 conversion of it.
+func loadAppPrivateKey(path string) (*rsa.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read GitHub App private key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key %s is not PEM encoded", path)
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+		}
+		return key, nil
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+		}
+		key, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("GitHub App private key must be an RSA key")
+		}
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %q", block.Type)
+	}
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var testAppKey *rsa.PrivateKey
+
+func appTestKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	if testAppKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatal(err)
+		}
+		testAppKey = key
+	}
+	return testAppKey
+}
+
+// writeAppKey saves the test key as PKCS#1 or PKCS#8 PEM and returns its path.
+func writeAppKey(t *testing.T, pkcs8 bool) string {
+	t.Helper()
+	key := appTestKey(t)
+	block := &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}
+	if pkcs8 {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+	}
+	path := filepath.Join(t.TempDir(), "app.pem")
+	if err := os.WriteFile(path, pem.EncodeToMemory(block), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// verifyAppJWT checks the RS256 signature and returns the claims.
+func verifyAppJWT(t *testing.T, jwt string) map[string]interface{} {
+	t.Helper()
+	parts := strings.Split(jwt, ".")
+	if len(parts) != 3 {
+		t.Fatalf("JWT has %d parts", len(parts))
+	}
+	var header map[string]string
+	decodeJWTPart(t, parts[0], &header)
+	if header["alg"] != "RS256" || header["typ"] != "JWT" {
+		t.Errorf("header = %v", header)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	if err := rsa.VerifyPKCS1v15(&appTestKey(t).PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+	var claims map[string]interface{}
+	decodeJWTPart(t, parts[1], &claims)
+	return claims
+}
+
+func decodeJWTPart(t *testing.T, part string, out interface{}) {
+	t.Helper()
+	data, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSignAppJWT(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		appID   string
+		pkcs8   bool
+		wantIss interface{}
+	}{
+		{name: "numeric app ID", appID: "12345", wantIss: float64(12345)},
+		{name: "client ID", appID: "Iv1.abcdef", wantIss: "Iv1.abcdef"},
+		{name: "PKCS#8 key", appID: "12345", pkcs8: true, wantIss: float64(12345)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds := &githubAppCredentials{AppID: tt.appID, InstallationID: 42, PrivateKeyPath: writeAppKey(t, tt.pkcs8)}
+			jwt, err := signAppJWT(creds, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims := verifyAppJWT(t, jwt)
+			if claims["iss"] != tt.wantIss {
+				t.Errorf("iss = %#v, want %#v", claims["iss"], tt.wantIss)
+			}
+			if iat := int64(claims["iat"].(float64)); iat != now.Add(-time.Minute).Unix() {
+				t.Errorf("iat = %d, want a minute before now", iat)
+			}
+			if exp := int64(claims["exp"].(float64)); exp != now.Add(appJWTLifetime).Unix() || appJWTLifetime >= 10*time.Minute {
+				t.Errorf("exp = %d, want %s after now and under ten minutes", exp, appJWTLifetime)
+			}
+		})
+	}
+}
+
+func TestSignAppJWTRejectsBadKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "key.pem")
+	os.WriteFile(path, []byte("not a key"), 0o600)
+	if _, err := signAppJWT(&githubAppCredentials{AppID: "1", PrivateKeyPath: path}, time.Now()); err == nil {
+		t.Fatal("signAppJWT accepted a file that is not PEM encoded")
+	}
+}
+
+// newInstallationTokenServer hands out numbered installation tokens valid for an hour,
+// checking the JWT each request is signed with.
+func newInstallationTokenServer(t *testing.T) (*App, *int32) {
+	var issued int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/app/installations/42/access_tokens", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		claims := verifyAppJWT(t, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if claims["iss"] != float64(12345) {
+			t.Errorf("iss = %v", claims["iss"])
+		}
+		n := atomic.AddInt32(&issued, 1)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"token":       fmt.Sprintf("ghs_%d", n),
+			"expires_at":  time.Now().Add(time.Hour).UTC().Format(time.RFC3339),
+			"permissions": map[string]string{"administration": "write", "contents": "write"},
+		})
+	})
+	app, _ := newTestApp(t, mux)
+	return app, &issued
+}
+
+func TestActiveGithubTokenRefreshMargin(t *testing.T) {
+	tests := []struct {
+		name       string
+		token      string
+		expiresIn  time.Duration
+		wantToken  string
+		wantIssued int32
+	}{
+		{name: "valid", token: "ghs_cached", expiresIn: 30 * time.Minute, wantToken: "ghs_cached"},
+		{name: "just outside the margin", token: "ghs_cached", expiresIn: installationTokenRefreshMargin + time.Minute, wantToken: "ghs_cached"},
+		{name: "within the margin", token: "ghs_cached", expiresIn: installationTokenRefreshMargin - time.Minute, wantToken: "ghs_1", wantIssued: 1},
+		{name: "expired", token: "ghs_cached", expiresIn: -time.Minute, wantToken: "ghs_1", wantIssued: 1},
+		{name: "no token yet", wantToken: "ghs_1", wantIssued: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, issued := newInstallationTokenServer(t)
+			acc := &githubAccount{
+				ID:                 "github.com/installation/42",
+				Host:               defaultGithubHost,
+				App:                &githubAppCredentials{AppID: "12345", InstallationID: 42, PrivateKeyPath: writeAppKey(t, false), OwnerType: "Organization"},
+				User:               &GithubUserProfile{Login: "acme"},
+				token:              tt.token,
+				installationExpiry: time.Now().Add(tt.expiresIn),
+			}
+			app.accounts = []*githubAccount{acc}
+			app.activeAccountID = acc.ID
+
+			token, err := app.activeGithubToken()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token != tt.wantToken {
+				t.Errorf("token = %q, want %q", token, tt.wantToken)
+			}
+			if got := atomic.LoadInt32(issued); got != tt.wantIssued {
+				t.Errorf("issued %d tokens, want %d", got, tt.wantIssued)
+			}
+			session := app.session()
+			if session.Token != tt.wantToken {
+				t.Errorf("session token = %q, want %q", session.Token, tt.wantToken)
+			}
+			if tt.wantIssued > 0 && (session.Permissions == nil || !session.Permissions.CanPush) {
+				t.Errorf("permissions = %+v, want those of the new token", session.Permissions)
+			}
+		})
+	}
+}
+
+func TestFetchGreenWallRepositoriesForInstallations(t *testing.T) {
+	tagged := `{"name":"wall","full_name":"acme/wall","topics":["greenwall"],"owner":{"login":"acme"}}`
+	untagged := `{"name":"other","full_name":"acme/other","topics":[],"owner":{"login":"acme"}}`
+	mux := http.NewServeMux()
+	mux.HandleFunc("/user/repos", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ghs_") {
+			http.Error(w, `{"message":"Resource not accessible by integration"}`, http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, "[%s,%s]", tagged, untagged)
+	})
+	mux.HandleFunc("/installation/repositories", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"total_count":2,"repositories":[%s,%s]}`, untagged, tagged)
+	})
+
+	tests := []struct {
+		name string
+		acc  *githubAccount
+	}{
+		{name: "user", acc: &githubAccount{ID: "github.com/octocat", Host: defaultGithubHost, User: &GithubUserProfile{Login: "octocat"}, token: "gho_user"}},
+		{name: "installation", acc: &githubAccount{
+			ID:                 "github.com/installation/42",
+			Host:               defaultGithubHost,
+			App:                &githubAppCredentials{AppID: "12345", InstallationID: 42},
+			User:               &GithubUserProfile{Login: "acme"},
+			token:              "ghs_app",
+			installationExpiry: time.Now().Add(time.Hour),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, _ := newTestApp(t, mux)
+			app.accounts = []*githubAccount{tt.acc}
+			app.activeAccountID = tt.acc.ID
+
+			repos, err := app.fetchGreenWallRepositories()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(repos) != 1 || repos[0].FullName != "acme/wall" {
+				t.Errorf("repos = %+v, want only acme/wall", repos)
+			}
+		})
+	}
+}
@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// The grid format is a plain-text drawing of the calendar that reads well in a diff:
+//
+//	greenwall-grid 1
+//	anchor 2024-01-01
+//	 ...1...
+//	..#2....
+//	(seven rows, Sunday first, one column per week)
+//	count 2024-01-09 5
+//
+// The anchor is the first day drawn; it sits in its weekday's row of the first column, and
+// every other cell is placed from it the way the calendar places days. A cell is '.' for no
+// contributions, '1'-'4' or '#' (4) for a shade, and ' ' outside the range. Shades stand for
+// the pen counts; a count line records a day whose count differs, so the file round-trips
+// exactly.
+const (
+	gridMagic         = "greenwall-grid"
+	gridFormatVersion = 1
+	gridFileExtension = ".txt"
+)
+
+const gridHeaderLines = 2
+
+type ExportContributionsGridRequest struct {
+	Contributions []ContributionDay `json:"contributions"`
+	Year          int               `json:"year,omitempty"`   // 0 draws Window or the year the contributions fall in
+	Window        *DesignWindow     `json:"window,omitempty"` // the design's rolling window
+}
+
+// ExportContributionsGrid saves the contributions in the plain-text grid format.
+func (a *App) ExportContributionsGrid(req ExportContributionsGridRequest) (*ExportContributionsResponse, error) {
+	text, err := encodeContributionGrid(&DesignDocument{Window: req.Window}, req.Contributions, req.Year)
+	if err != nil {
+		return nil, err
+	}
+
+	filePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出文本网格",
+		DefaultFilename: "design.grid" + gridFileExtension,
+		Filters: []runtime.FileFilter{
+			{DisplayName: "文本网格 (*.txt)", Pattern: "*" + gridFileExtension},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if filePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+
+	if err := os.WriteFile(filePath, []byte(text), 0o644); err != nil {
+		return nil, fmt.Errorf("write grid to file: %w", err)
+	}
+	return &ExportContributionsResponse{FilePath: filePath}, nil
+}
+
+// encodeContributionGrid draws the contributions on the calendar of year, or when year is 0
+// on the design's window or year, else the year or, uncapped, the span they cover. A day
+// outside the calendar is an error rather than left out of the file.
+func encodeContributionGrid(design *DesignDocument, contributions []ContributionDay, year int) (string, error) {
+	for _, day := range contributions {
+		if day.Count < 0 {
+			return "", fmt.Errorf("invalid contribution count for %s: %d", day.Date, day.Count)
+		}
+	}
+	counts, first, last, err := countCalendarDays(contributions)
+	if err != nil {
+		return "", err
+	}
+	if year == 0 && design != nil && design.Window == nil {
+		year = design.Year
+	}
+	windowed := year == 0 && design != nil && design.Window != nil
+	var layout *calendarLayout
+	if !windowed && year == 0 && !first.IsZero() && first.Year() != last.Year() {
+		// Unlike a preview, a grid keeps every week of days across several years.
+		layout = spanCalendarLayout(counts, first, last)
+	} else if layout, err = newDesignCalendarLayout(design, contributions, year); err != nil {
+		return "", err
+	}
+	for _, day := range contributions {
+		date, _ := time.Parse("2006-01-02", day.Date) // checked by countCalendarDays
+		if day.Count == 0 || (!date.Before(layout.start) && !date.After(layout.end)) {
+			continue
+		}
+		if windowed {
+			return "", fmt.Errorf("%s is outside the design's window; export without the window to keep every day", day.Date)
+		}
+		return "", fmt.Errorf("%s is not in %d; export without a year to keep every day", day.Date, year)
+	}
+
+	rows := make([][]byte, 7)
+	for row := range rows {
+		rows[row] = bytes.Repeat([]byte{' '}, layout.weeks)
+	}
+	var overrides []string
+	layout.each(func(date time.Time, count int) {
+		column, row := layout.position(date)
+		level := contributionLevel(count)
+		rows[row][column] = gridLevelChar(level)
+		if count != penLevelCounts[level] {
+			overrides = append(overrides, fmt.Sprintf("count %s %d", date.Format("2006-01-02"), count))
+		}
+	})
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s %d\nanchor %s\n", gridMagic, gridFormatVersion, layout.start.Format("2006-01-02"))
+	for _, row := range rows {
+		out.Write(bytes.TrimRight(row, " "))
+		out.WriteByte('\n')
+	}
+	for _, line := range overrides {
+		out.WriteString(line)
+		out.WriteByte('\n')
+	}
+	return out.String(), nil
+}
+
+func gridLevelChar(level int) byte {
+	if level == 0 {
+		return '.'
+	}
+	return byte('0' + level)
+}
+
+// gridLine is a line of a grid file and where it starts in the data.
+type gridLine struct {
+	text   string
+	offset int64
+}
+
+// scanGrid reads a grid starting at offset, adding each drawn day to the validator's entries.
+func (v *contributionsValidator) scanGrid(offset int64) error {
+	var lines []gridLine
+	for rest := v.data[offset:]; len(rest) > 0; {
+		text := rest
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			text, rest = rest[:i], rest[i+1:]
+		} else {
+			rest = nil
+		}
+		lines = append(lines, gridLine{text: strings.TrimSuffix(string(text), "\r"), offset: offset})
+		offset += int64(len(text)) + 1
+	}
+	headerError := func(line gridLine, message string) error {
+		return &ImportValidationError{Issues: []ImportIssue{v.issue(-1, line.offset, message)}}
+	}
+
+	if fields := strings.Fields(lines[0].text); len(fields) != 2 || fields[0] != gridMagic {
+		return headerError(lines[0], fmt.Sprintf("expected %q on the first line", fmt.Sprintf("%s %d", gridMagic, gridFormatVersion)))
+	} else if version, err := strconv.Atoi(fields[1]); err != nil || version < 1 {
+		return headerError(lines[0], fmt.Sprintf("invalid grid version %q", fields[1]))
+	} else if version > gridFormatVersion {
+		return headerError(lines[0], fmt.Sprintf("the grid was saved by a newer GreenWall (format version %d); update to open it", version))
+	}
+	anchorLine := lines[0] // a file of one line is missing the anchor after it
+	var fields []string
+	if len(lines) > 1 {
+		anchorLine = lines[1]
+		fields = strings.Fields(anchorLine.text)
+	}
+	if len(fields) != 2 || fields[0] != "anchor" {
+		return headerError(anchorLine, "expected \"anchor YYYY-MM-DD\" on the second line")
+	}
+	anchor, err := time.Parse("2006-01-02", fields[1])
+	if err != nil {
+		return headerError(anchorLine, fmt.Sprintf("invalid anchor date %q: use an existing date as YYYY-MM-DD", fields[1]))
+	}
+	if len(lines) < gridHeaderLines+7 {
+		return headerError(lines[len(lines)-1], "a grid has seven rows after the anchor")
+	}
+
+	cells := make(map[string]*importEntry)
+	weekStart := anchor.AddDate(0, 0, -int(anchor.Weekday()))
+	for row, line := range lines[gridHeaderLines : gridHeaderLines+7] {
+		column := -1
+		for i, char := range line.text {
+			if column++; char == ' ' || char == '.' {
+				continue
+			}
+			date := weekStart.AddDate(0, 0, column*7+row)
+			issue := v.issue(-1, line.offset+int64(i), "")
+			issue.Date = date.Format("2006-01-02")
+			level := 4
+			if char != '#' {
+				level = int(char - '0')
+			}
+			switch {
+			case level < 1 || level > 4:
+				v.gridError(issue, fmt.Sprintf("unexpected %q: use '.', '1'-'4' or '#'", char))
+			case date.Before(anchor):
+				v.gridError(issue, fmt.Sprintf("%s is before the anchor date %s", issue.Date, fields[1]))
+			default:
+				cells[issue.Date] = &importEntry{issue: issue, day: DesignDay{Date: issue.Date, Level: level}, count: penLevelCounts[level]}
+			}
+		}
+	}
+
+	overridden := make(map[string]bool)
+	for _, line := range lines[gridHeaderLines+7:] {
+		fields := strings.Fields(line.text)
+		if len(fields) == 0 {
+			continue
+		}
+		issue := v.issue(-1, line.offset, "")
+		if len(fields) != 3 || fields[0] != "count" {
+			v.gridError(issue, "expected \"count YYYY-MM-DD N\" after the rows")
+			continue
+		}
+		issue.Date = fields[1]
+		cell, ok := cells[fields[1]]
+		count, err := strconv.Atoi(fields[2])
+		switch {
+		case err != nil || count < 1:
+			v.gridError(issue, fmt.Sprintf("invalid count %q: use a positive whole number", fields[2]))
+		case !ok:
+			v.gridError(issue, fmt.Sprintf("%s is not drawn in the grid", fields[1]))
+		case overridden[fields[1]]:
+			v.gridError(issue, fmt.Sprintf("%s has more than one count", fields[1]))
+		case contributionLevel(count) != cell.day.Level:
+			v.gridError(issue, fmt.Sprintf("a count of %d is drawn as %c, not %c", count, gridLevelChar(contributionLevel(count)), gridLevelChar(cell.day.Level)))
+		default:
+			overridden[fields[1]] = true
+			cell.day = DesignDay{Date: fields[1], Count: count}
+			cell.count = count
+		}
+	}
+
+	dates := make([]string, 0, len(cells))
+	for date := range cells {
+		dates = append(dates, date)
+	}
+	sort.Strings(dates)
+	for _, date := range dates {
+		v.entries = append(v.entries, *cells[date])
+	}
+	v.report.Entries = len(dates)
+	return nil
+}
+
+func (v *contributionsValidator) gridError(issue ImportIssue, message string) {
+	if len(v.errs) < maxReportedImportErrors {
+		issue.Message = message
+		v.errs = append(v.errs, issue)
+	}
+}
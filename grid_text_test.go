@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestContributionGridRoundTrip(t *testing.T) {
+	tests := []struct {
+		name          string
+		contributions []ContributionDay
+		year          int
+	}{
+		{
+			name: "shades and exact counts",
+			contributions: []ContributionDay{
+				{Date: "2024-01-01", Count: 1},
+				{Date: "2024-02-29", Count: 3},
+				{Date: "2024-07-04", Count: 7},
+				{Date: "2024-12-31", Count: 40},
+			},
+			year: 2024,
+		},
+		{
+			name:          "year of the days",
+			contributions: []ContributionDay{{Date: "2023-06-15", Count: 9}},
+		},
+		{
+			name:          "several years",
+			contributions: []ContributionDay{{Date: "2021-03-01", Count: 2}, {Date: "2024-06-15", Count: 6}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := encodeContributionGrid(nil, tt.contributions, tt.year)
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, got, report, err := validateContributionsFile([]byte(text), importValidationOptions{})
+			if err != nil {
+				t.Fatalf("%v\n%s", err, text)
+			}
+			if !reflect.DeepEqual(got, tt.contributions) {
+				t.Errorf("read back %v, want %v\n%s", got, tt.contributions, text)
+			}
+			if len(report.Warnings) != 0 || len(report.Fixes) != 0 {
+				t.Errorf("report = %+v, want nothing changed", report)
+			}
+		})
+	}
+}
+
+func TestEncodeContributionGridRejectsDaysOutsideTheYear(t *testing.T) {
+	contributions := []ContributionDay{{Date: "2023-12-31", Count: 1}, {Date: "2024-01-01", Count: 1}}
+	if _, err := encodeContributionGrid(nil, contributions, 2024); err == nil || !strings.Contains(err.Error(), "2023-12-31 is not in 2024") {
+		t.Fatalf("error = %v, want one naming the day outside 2024", err)
+	}
+	// A day without contributions has nothing to lose.
+	if _, err := encodeContributionGrid(nil, []ContributionDay{{Date: "2023-12-31"}, {Date: "2024-01-01", Count: 1}}, 2024); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestEncodeContributionGridUsesTheDesignWindow(t *testing.T) {
+	design := &DesignDocument{Window: &DesignWindow{End: "2024-06-15", Weeks: 4}}
+	text, err := encodeContributionGrid(design, []ContributionDay{{Date: "2024-06-01", Count: 1}}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, "\nanchor 2024-05-19\n") {
+		t.Errorf("grid is not anchored at the start of the window:\n%s", text)
+	}
+	_, err = encodeContributionGrid(design, []ContributionDay{{Date: "2024-05-18", Count: 1}}, 0)
+	if err == nil || !strings.Contains(err.Error(), "2024-05-18 is outside the design's window") {
+		t.Fatalf("error = %v, want one naming the day before the window", err)
+	}
+}
+
+func TestScanGridErrors(t *testing.T) {
+	const rows = "#\n.\n.\n.\n.\n.\n.\n"
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{
+			name:    "anchor missing",
+			data:    "greenwall-grid 1\n\n" + rows,
+			wantErr: `line 2, column 1 (offset 17): expected "anchor YYYY-MM-DD" on the second line`,
+		},
+		{
+			name:    "invalid anchor",
+			data:    "greenwall-grid 1\nanchor 2024-13-01\n" + rows,
+			wantErr: `line 2, column 1 (offset 17): invalid anchor date "2024-13-01"`,
+		},
+		{
+			name:    "too few rows",
+			data:    "greenwall-grid 1\nanchor 2024-01-07\n#\n",
+			wantErr: "a grid has seven rows after the anchor",
+		},
+		{
+			name:    "multi-byte character",
+			data:    "greenwall-grid 1\nanchor 2024-01-07\n.é#\n.\n.\n.\n.\n.\n.\n",
+			wantErr: `the file has 1 problem(s): line 3, column 2 (offset 36): unexpected 'é'`,
+		},
+		{
+			name:    "day before the anchor",
+			data:    "greenwall-grid 1\nanchor 2024-01-09\n" + rows,
+			wantErr: "2024-01-07 is before the anchor date 2024-01-09",
+		},
+		{
+			name:    "count of a day not drawn",
+			data:    "greenwall-grid 1\nanchor 2024-01-07\n" + rows + "count 2024-01-08 2\n",
+			wantErr: "2024-01-08 is not drawn in the grid",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, _, err := validateContributionsFile([]byte(tt.data), importValidationOptions{})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestScanGridMultiByteCharacterKeepsColumns(t *testing.T) {
+	data := "greenwall-grid 1\nanchor 2024-01-07\n.é.9\n.\n.\n.\n.\n.\n.\n"
+	_, _, _, err := validateContributionsFile([]byte(data), importValidationOptions{})
+	var validationErr *ImportValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("error = %v, want an *ImportValidationError", err)
+	}
+	// One problem for the two bytes of 'é', and the '9' still in the fourth week.
+	want := []ImportIssue{
+		{Index: -1, Offset: 36, Line: 3, Column: 2, Date: "2024-01-14", Message: "unexpected 'é': use '.', '1'-'4' or '#'"},
+		{Index: -1, Offset: 39, Line: 3, Column: 4, Date: "2024-01-28", Message: "unexpected '9': use '.', '1'-'4' or '#'"},
+	}
+	if !reflect.DeepEqual(validationErr.Issues, want) {
+		t.Errorf("issues = %+v, want %+v", validationErr.Issues, want)
+	}
+}
@@ -0,0 +1,552 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Image conversion modes, as in the editor's image import card.
+const (
+	imageModeAuto   = "auto"   // five shades from the brightness quantiles
+	imageModeBinary = "binary" // everything above Otsu's threshold at full strength
+)
+
+const (
+	imageDitherNone           = "none"
+	imageDitherFloydSteinberg = "floyd-steinberg"
+	imageDitherOrdered        = "ordered"
+)
+
+const (
+	maxImageGridWidth     = 52
+	maxImagePixels        = 40_000_000
+	defaultStrokeRecovery = 12
+	maxStrokeRecovery     = 64
+	maxImageDilation      = 3
+)
+
+// bayer4 is the 4×4 ordered-dithering matrix.
+var bayer4 = [4][4]float64{
+	{0, 8, 2, 10},
+	{12, 4, 14, 6},
+	{3, 11, 1, 9},
+	{15, 7, 13, 5},
+}
+
+type ImageImportRequest struct {
+	FilePath  string `json:"filePath,omitempty"`  // empty asks with a file dialog
+	Width     int    `json:"width,omitempty"`     // weeks, 1–52; 0 follows the aspect ratio
+	Height    int    `json:"height,omitempty"`    // days, 1–7; 0 follows the aspect ratio
+	Mode      string `json:"mode,omitempty"`      // auto (default) or binary
+	Invert    *bool  `json:"invert,omitempty"`    // nil inverts, so dark pixels become contributions
+	Threshold int    `json:"threshold,omitempty"` // brightness at or below this is empty; 0 disables it
+	Nearest   bool   `json:"nearest,omitempty"`   // sample single pixels instead of averaging areas
+	// StrokeRecovery lowers Otsu's threshold in binary mode when the result is too sparse.
+	// nil uses 12, as in the editor.
+	StrokeRecovery    *int   `json:"strokeRecovery,omitempty"`
+	SecondaryRecovery int    `json:"secondaryRecovery,omitempty"` // further lowering after StrokeRecovery
+	Dilation          int    `json:"dilation,omitempty"`          // thickens strokes by this many cells, 0–3
+	Dither            string `json:"dither,omitempty"`            // none (default), floyd-steinberg or ordered; auto mode only
+	Year              int    `json:"year,omitempty"`              // year to place the image in; 0 is the current year
+	Column            int    `json:"column,omitempty"`            // week column of the image's left edge
+	Row               int    `json:"row,omitempty"`               // weekday row of the image's top edge, 0 is Sunday
+}
+
+type ImageImportResponse struct {
+	FilePath      string            `json:"filePath"`
+	Width         int               `json:"width"`
+	Height        int               `json:"height"`
+	Grid          [][]int           `json:"grid"` // commit counts, Height rows of Width cells
+	Contributions []ContributionDay `json:"contributions"`
+	SkippedCells  int               `json:"skippedCells"` // lit cells outside the year or in the future
+}
+
+// ImportImage converts a PNG, JPEG or GIF image into contributions.
+func (a *App) ImportImage(req ImageImportRequest) (*ImageImportResponse, error) {
+	filePath := strings.TrimSpace(req.FilePath)
+	if filePath == "" {
+		var err error
+		filePath, err = runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+			Title: "导入图片",
+			Filters: []runtime.FileFilter{
+				{DisplayName: "图片 (*.png;*.jpg;*.jpeg;*.gif)", Pattern: "*.png;*.jpg;*.jpeg;*.gif"},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("open file dialog: %w", err)
+		}
+		if filePath == "" {
+			return nil, fmt.Errorf("import cancelled")
+		}
+	}
+	req.FilePath = filePath
+	return importImageFile(req, time.Now())
+}
+
+func importImageFile(req ImageImportRequest, now time.Time) (*ImageImportResponse, error) {
+	data, err := os.ReadFile(req.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf("read image: %w", err)
+	}
+	img, err := decodeImportImage(data)
+	if err != nil {
+		return nil, err
+	}
+	grid, err := convertImageToGrid(img, req)
+	if err != nil {
+		return nil, err
+	}
+	contributions, skipped, err := placeImageGrid(grid, req, now)
+	if err != nil {
+		return nil, err
+	}
+	return &ImageImportResponse{
+		FilePath:      req.FilePath,
+		Width:         len(grid[0]),
+		Height:        len(grid),
+		Grid:          grid,
+		Contributions: contributions,
+		SkippedCells:  skipped,
+	}, nil
+}
+
+func decodeImportImage(data []byte) (image.Image, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("unsupported image: use PNG, JPEG or GIF (%v)", err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, fmt.Errorf("the image is empty")
+	}
+	if config.Width*config.Height > maxImagePixels {
+		return nil, fmt.Errorf("the image is too large (%d×%d)", config.Width, config.Height)
+	}
+	// Animated GIFs decode to their first frame.
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("decode %s image: %w", format, err)
+	}
+	return img, nil
+}
+
+// convertImageToGrid follows the editor's conversion: shrink the image to the grid, take
+// the (inverted) brightness, drop what is below the threshold, stretch the rest to 0–255
+// and quantise it into the pens' commit counts.
+func convertImageToGrid(img image.Image, req ImageImportRequest) ([][]int, error) {
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if mode == "" {
+		mode = imageModeAuto
+	}
+	if mode != imageModeAuto && mode != imageModeBinary {
+		return nil, fmt.Errorf("unsupported image mode %q (use auto or binary)", req.Mode)
+	}
+	dither := strings.ToLower(strings.TrimSpace(req.Dither))
+	switch dither {
+	case "", imageDitherNone:
+		dither = ""
+	case imageDitherFloydSteinberg, imageDitherOrdered:
+		if mode == imageModeBinary {
+			return nil, fmt.Errorf("dithering only applies to auto mode")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported dithering %q (use none, floyd-steinberg or ordered)", req.Dither)
+	}
+	if req.Threshold < 0 || req.Threshold > 255 {
+		return nil, fmt.Errorf("threshold must be between 0 and 255")
+	}
+	recovery := defaultStrokeRecovery
+	if req.StrokeRecovery != nil {
+		recovery = *req.StrokeRecovery
+	}
+	if recovery < 0 || recovery > maxStrokeRecovery || req.SecondaryRecovery < 0 || req.SecondaryRecovery > maxStrokeRecovery {
+		return nil, fmt.Errorf("stroke recovery must be between 0 and %d", maxStrokeRecovery)
+	}
+	if req.Dilation < 0 || req.Dilation > maxImageDilation {
+		return nil, fmt.Errorf("dilation must be between 0 and %d", maxImageDilation)
+	}
+
+	bounds := img.Bounds()
+	w, h := float64(bounds.Dx()), float64(bounds.Dy())
+	width := clampInt(int(math.Round(w/h*7)), 1, maxImageGridWidth)
+	if req.Width > 0 {
+		width = clampInt(req.Width, 1, maxImageGridWidth)
+	}
+	height := clampInt(int(math.Round(h/w*float64(width))), 1, 7)
+	if req.Height > 0 {
+		height = clampInt(req.Height, 1, 7)
+	}
+
+	invert := req.Invert == nil || *req.Invert
+	values := make([][]int, height)
+	for y := range values {
+		values[y] = make([]int, width)
+		for x := range values[y] {
+			value := int(math.Round(sampleBrightness(img, x, y, width, height, req.Nearest)))
+			if invert {
+				value = 255 - value
+			}
+			if req.Threshold > 0 && value <= req.Threshold {
+				value = 0
+			}
+			values[y][x] = value
+		}
+	}
+	normaliseBrightness(values)
+
+	var levels [][]int
+	switch {
+	case mode == imageModeBinary:
+		levels = binaryLevels(values, recovery, req.SecondaryRecovery)
+	case dither == imageDitherFloydSteinberg:
+		levels = floydSteinbergLevels(values)
+	case dither == imageDitherOrdered:
+		levels = orderedLevels(values)
+	default:
+		levels = quantileLevels(values)
+	}
+	if req.Dilation > 0 {
+		levels = dilateLevels(levels, req.Dilation)
+	}
+
+	for _, row := range levels {
+		for x, level := range row {
+			row[x] = penLevelCounts[level]
+		}
+	}
+	return levels, nil
+}
+
+// sampleBrightness returns the luma of the area of img under grid cell (x, y). Transparent
+// pixels count as white, so a transparent background stays empty.
+func sampleBrightness(img image.Image, x, y, width, height int, nearest bool) float64 {
+	bounds := img.Bounds()
+	x0 := bounds.Min.X + x*bounds.Dx()/width
+	x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+	y0 := bounds.Min.Y + y*bounds.Dy()/height
+	y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+	if nearest || x1 <= x0 || y1 <= y0 {
+		px := bounds.Min.X + int((float64(x)+0.5)*float64(bounds.Dx())/float64(width))
+		py := bounds.Min.Y + int((float64(y)+0.5)*float64(bounds.Dy())/float64(height))
+		return pixelLuma(img, px, py)
+	}
+
+	var sum float64
+	for py := y0; py < y1; py++ {
+		for px := x0; px < x1; px++ {
+			sum += pixelLuma(img, px, py)
+		}
+	}
+	return sum / float64((x1-x0)*(y1-y0))
+}
+
+func pixelLuma(img image.Image, x, y int) float64 {
+	r, g, b, alpha := img.At(x, y).RGBA()
+	// Composite the premultiplied colour over white and scale to 0–255.
+	white := float64(0xffff - alpha)
+	red := (float64(r) + white) / 257
+	green := (float64(g) + white) / 257
+	blue := (float64(b) + white) / 257
+	return 0.299*red + 0.587*green + 0.114*blue
+}
+
+// normaliseBrightness stretches the non-zero values to span 1–255.
+func normaliseBrightness(values [][]int) {
+	minValue, maxValue := 0, 0
+	for _, row := range values {
+		for _, value := range row {
+			if value <= 0 {
+				continue
+			}
+			if minValue == 0 || value < minValue {
+				minValue = value
+			}
+			if value > maxValue {
+				maxValue = value
+			}
+		}
+	}
+	for _, row := range values {
+		for x, value := range row {
+			switch {
+			case value <= 0:
+				row[x] = 0
+			case maxValue == minValue:
+				row[x] = 255
+			default:
+				row[x] = clampInt(int(math.Round(float64(value-minValue)/float64(maxValue-minValue)*255)), 0, 255)
+			}
+		}
+	}
+}
+
+// quantileLevels gives each shade a similar share of the lit cells; without any variation
+// the brightness maps to the shades linearly.
+func quantileLevels(values [][]int) [][]int {
+	var all, lit []int
+	for _, row := range values {
+		all = append(all, row...)
+		for _, value := range row {
+			if value > 0 {
+				lit = append(lit, value)
+			}
+		}
+	}
+	source := all
+	if hasVariance(lit) {
+		source = lit
+	}
+	thresholds := quantileThresholds(source, 5)
+	variance := hasVariance(source)
+
+	return mapLevels(values, func(x, y, value int) int {
+		if !variance {
+			return clampInt(int(math.Round(float64(value)/255*4)), 0, 4)
+		}
+		level := 0
+		for _, threshold := range thresholds {
+			if value <= threshold {
+				break
+			}
+			level++
+		}
+		return level
+	})
+}
+
+func quantileThresholds(values []int, buckets int) []int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	thresholds := make([]int, 0, buckets-1)
+	for i := 1; i < buckets; i++ {
+		index := len(sorted) * i / buckets
+		if index < len(sorted) {
+			thresholds = append(thresholds, sorted[index])
+		} else {
+			thresholds = append(thresholds, 255)
+		}
+	}
+	return thresholds
+}
+
+func hasVariance(values []int) bool {
+	for _, value := range values {
+		if value != values[0] {
+			return true
+		}
+	}
+	return false
+}
+
+// binaryLevels lights the cells above Otsu's threshold. Stroke recovery retries with a
+// lower threshold when that lights more cells or the first result is very sparse.
+func binaryLevels(values [][]int, recovery, secondary int) [][]int {
+	var all, lit []int
+	for _, row := range values {
+		all = append(all, row...)
+		for _, value := range row {
+			if value > 0 {
+				lit = append(lit, value)
+			}
+		}
+	}
+	source := all
+	if hasVariance(lit) {
+		source = lit
+	}
+	otsu := otsuThreshold(source)
+
+	binarise := func(threshold int) ([][]int, int) {
+		active := 0
+		levels := mapLevels(values, func(x, y, value int) int {
+			if value > threshold {
+				active++
+				return 4
+			}
+			return 0
+		})
+		return levels, active
+	}
+
+	chosen, active := binarise(otsu)
+	if recovery > 0 {
+		relaxed, relaxedActive := binarise(clampInt(otsu-recovery, 0, 255))
+		sparse := float64(len(all)) / 20
+		if relaxedActive > active || float64(active) < sparse {
+			chosen, active = relaxed, relaxedActive
+		}
+	}
+	if secondary > 0 {
+		relaxed, relaxedActive := binarise(clampInt(otsu-recovery-secondary, 0, 255))
+		if relaxedActive > active {
+			chosen = relaxed
+		}
+	}
+	return chosen
+}
+
+func otsuThreshold(values []int) int {
+	if len(values) == 0 {
+		return 128
+	}
+	var hist [256]int
+	sum := 0
+	for _, value := range values {
+		hist[clampInt(value, 0, 255)]++
+		sum += value
+	}
+
+	total := len(values)
+	sumB, weightB := 0, 0
+	best, threshold := 0.0, 128
+	for t := 0; t < 256; t++ {
+		weightB += hist[t]
+		if weightB == 0 {
+			continue
+		}
+		weightF := total - weightB
+		if weightF == 0 {
+			break
+		}
+		sumB += t * hist[t]
+		meanB := float64(sumB) / float64(weightB)
+		meanF := float64(sum-sumB) / float64(weightF)
+		between := float64(weightB) * float64(weightF) * (meanB - meanF) * (meanB - meanF)
+		if between > best {
+			best, threshold = between, t
+		}
+	}
+	return threshold
+}
+
+// floydSteinbergLevels quantises to the five shades, pushing each cell's rounding error
+// onto the cells to its right and below.
+func floydSteinbergLevels(values [][]int) [][]int {
+	height, width := len(values), len(values[0])
+	work := make([][]float64, height)
+	for y := range work {
+		work[y] = make([]float64, width)
+		for x, value := range values[y] {
+			work[y][x] = float64(value) / 255 * 4
+		}
+	}
+	spread := func(x, y int, amount float64) {
+		if x >= 0 && x < width && y < height {
+			work[y][x] += amount
+		}
+	}
+
+	levels := make([][]int, height)
+	for y := range levels {
+		levels[y] = make([]int, width)
+		for x := range levels[y] {
+			level := clampInt(int(math.Round(work[y][x])), 0, 4)
+			levels[y][x] = level
+			quantError := work[y][x] - float64(level)
+			spread(x+1, y, quantError*7/16)
+			spread(x-1, y+1, quantError*3/16)
+			spread(x, y+1, quantError*5/16)
+			spread(x+1, y+1, quantError*1/16)
+		}
+	}
+	return levels
+}
+
+// orderedLevels quantises to the five shades, rounding up or down by the Bayer matrix.
+func orderedLevels(values [][]int) [][]int {
+	return mapLevels(values, func(x, y, value int) int {
+		scaled := float64(value) / 255 * 4
+		level := math.Floor(scaled)
+		if scaled-level > (bayer4[y%4][x%4]+0.5)/16 {
+			level++
+		}
+		return clampInt(int(level), 0, 4)
+	})
+}
+
+// dilateLevels gives every cell the darkest shade within radius cells of it.
+func dilateLevels(levels [][]int, radius int) [][]int {
+	height, width := len(levels), len(levels[0])
+	return mapLevels(levels, func(x, y, _ int) int {
+		darkest := 0
+		for dy := -radius; dy <= radius; dy++ {
+			for dx := -radius; dx <= radius; dx++ {
+				nx, ny := x+dx, y+dy
+				if nx >= 0 && nx < width && ny >= 0 && ny < height && levels[ny][nx] > darkest {
+					darkest = levels[ny][nx]
+				}
+			}
+		}
+		return darkest
+	})
+}
+
+func mapLevels(values [][]int, fn func(x, y, value int) int) [][]int {
+	levels := make([][]int, len(values))
+	for y, row := range values {
+		levels[y] = make([]int, len(row))
+		for x, value := range row {
+			levels[y][x] = fn(x, y, value)
+		}
+	}
+	return levels
+}
+
+// placeImageGrid turns the grid into dated contributions with its top-left cell at the
+// given week column and weekday row of the year. Cells outside the year, or after today
+// in the current year, are skipped as when pasting in the editor.
+func placeImageGrid(grid [][]int, req ImageImportRequest, now time.Time) ([]ContributionDay, int, error) {
+	year := req.Year
+	if year == 0 {
+		year = now.Year()
+	}
+	if req.Column < 0 || req.Column > 52 {
+		return nil, 0, fmt.Errorf("column must be between 0 and 52")
+	}
+	if req.Row < 0 || req.Row+len(grid) > 7 {
+		return nil, 0, fmt.Errorf("the image needs rows %d–%d, but a week has 7", req.Row, req.Row+len(grid)-1)
+	}
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	startRow := int(yearStart.Weekday())
+
+	contributions := []ContributionDay{}
+	skipped := 0
+	for y, row := range grid {
+		for x, count := range row {
+			if count == 0 {
+				continue
+			}
+			date := yearStart.AddDate(0, 0, (req.Column+x)*7+req.Row+y-startRow)
+			if date.Before(yearStart) || date.After(yearEnd) || year == now.Year() && date.After(today) {
+				skipped++
+				continue
+			}
+			contributions = append(contributions, ContributionDay{Date: date.Format("2006-01-02"), Count: count})
+		}
+	}
+	sort.Slice(contributions, func(i, j int) bool { return contributions[i].Date < contributions[j].Date })
+	return contributions, skipped, nil
+}
+
+func clampInt(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
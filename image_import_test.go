@@ -0,0 +1,226 @@
+package main
+
+import (
+	"image"
+	"image/color"
+	"reflect"
+	"testing"
+)
+
+// grayRow returns a one-pixel-high image with the given brightness values.
+func grayRow(values ...uint8) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, len(values), 1))
+	copy(img.Pix, values)
+	return img
+}
+
+// repeatInt returns n copies of value followed by rest.
+func repeatInt(value, n int, rest ...int) []int {
+	values := make([]int, 0, n+len(rest))
+	for i := 0; i < n; i++ {
+		values = append(values, value)
+	}
+	return append(values, rest...)
+}
+
+func TestOtsuThreshold(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   int
+	}{
+		{name: "empty", want: 128},
+		{name: "constant", values: []int{50, 50}, want: 128},
+		{name: "black and white", values: []int{0, 255}, want: 0},
+		{name: "two groups", values: repeatInt(100, 4, 250, 250, 250, 250), want: 100},
+		{name: "faint strokes join the background", values: []int{80, 80, 100, 100, 100, 100, 250, 250, 250, 250}, want: 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := otsuThreshold(tt.values); got != tt.want {
+				t.Errorf("otsuThreshold(%v) = %d, want %d", tt.values, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuantileLevels(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []int
+		want   []int
+	}{
+		{
+			name:   "two tones",
+			values: repeatInt(0, 9, 255),
+			want:   repeatInt(0, 9, 4),
+		},
+		{
+			name:   "lit cells share the shades",
+			values: []int{0, 25, 50, 75, 100, 125, 150, 175, 200, 225, 250},
+			want:   []int{0, 0, 0, 0, 1, 1, 2, 2, 3, 3, 4},
+		},
+		{
+			name:   "no variation maps linearly",
+			values: []int{128, 128},
+			want:   []int{2, 2},
+		},
+		{
+			name:   "blank",
+			values: []int{0, 0, 0},
+			want:   []int{0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := quantileLevels([][]int{tt.values})
+			if !reflect.DeepEqual(got[0], tt.want) {
+				t.Errorf("quantileLevels(%v) = %v, want %v", tt.values, got[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestBinaryLevels(t *testing.T) {
+	// Otsu's threshold is 100: four strokes above it in a hundred cells is sparse, so
+	// recovery lowers it to light the 100s, and the secondary pass the 80s as well.
+	values := repeatInt(0, 90, 80, 80, 100, 100, 100, 100, 250, 250, 250, 250)
+	tests := []struct {
+		name       string
+		recovery   int
+		secondary  int
+		wantActive int
+	}{
+		{name: "otsu only", wantActive: 4},
+		{name: "sparse result takes the relaxed threshold", recovery: defaultStrokeRecovery, wantActive: 8},
+		{name: "secondary recovery", recovery: defaultStrokeRecovery, secondary: 10, wantActive: 10},
+		{name: "secondary pass that lights nothing more", recovery: defaultStrokeRecovery, secondary: 1, wantActive: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			levels := binaryLevels([][]int{values}, tt.recovery, tt.secondary)
+			active := 0
+			for _, level := range levels[0] {
+				switch level {
+				case 4:
+					active++
+				case 0:
+				default:
+					t.Fatalf("binaryLevels() produced level %d", level)
+				}
+			}
+			if active != tt.wantActive {
+				t.Errorf("binaryLevels() lit %d cells, want %d", active, tt.wantActive)
+			}
+		})
+	}
+}
+
+func TestConvertImageToGridSize(t *testing.T) {
+	tests := []struct {
+		name                string
+		width, height       int
+		reqWidth, reqHeight int
+		wantW, wantH        int
+	}{
+		{name: "wide image is capped at a year", width: 100, height: 10, wantW: maxImageGridWidth, wantH: 5},
+		{name: "tall image is capped at a week", width: 10, height: 100, wantW: 1, wantH: 7},
+		{name: "square image", width: 70, height: 70, wantW: 7, wantH: 7},
+		{name: "requested size is clamped", width: 70, height: 70, reqWidth: 80, reqHeight: 9, wantW: maxImageGridWidth, wantH: 7},
+		{name: "requested width sets the height", width: 40, height: 20, reqWidth: 10, wantW: 10, wantH: 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img := image.NewRGBA(image.Rect(0, 0, tt.width, tt.height))
+			grid, err := convertImageToGrid(img, ImageImportRequest{Width: tt.reqWidth, Height: tt.reqHeight})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(grid) != tt.wantH || len(grid[0]) != tt.wantW {
+				t.Errorf("grid is %d×%d, want %d×%d", len(grid[0]), len(grid), tt.wantW, tt.wantH)
+			}
+		})
+	}
+}
+
+func TestConvertImageToGridLevels(t *testing.T) {
+	noInvert := false
+	white, light, mid, black := uint8(255), uint8(200), uint8(100), uint8(0)
+	tests := []struct {
+		name string
+		img  image.Image
+		req  ImageImportRequest
+		want []int
+	}{
+		{
+			name: "dark ink on white paper",
+			img:  grayRow(white, black, white, white, white, white, white, white, white, white),
+			want: []int{0, 9, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name: "without inverting light pixels are lit",
+			img:  grayRow(black, white, black, black, black, black, black, black, black, black),
+			req:  ImageImportRequest{Invert: &noInvert},
+			want: []int{0, 9, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			// Normalising stretches the faintest pixel down to empty.
+			name: "shades of grey",
+			img:  grayRow(black, light, mid, white, white, white, white, white, white, white),
+			want: []int{3, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name: "threshold drops the faint pixels",
+			img:  grayRow(black, light, mid, white, white, white, white, white, white, white),
+			req:  ImageImportRequest{Threshold: 60},
+			want: []int{9, 0, 0, 0, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name: "transparent pixels count as white",
+			img: func() image.Image {
+				img := image.NewRGBA(image.Rect(0, 0, 10, 1))
+				img.Set(3, 0, color.RGBA{A: 255})
+				return img
+			}(),
+			want: []int{0, 0, 0, 9, 0, 0, 0, 0, 0, 0},
+		},
+		{
+			name: "binary mode",
+			img:  grayRow(black, light, mid, white, white, white, white, white, white, white),
+			req:  ImageImportRequest{Mode: imageModeBinary},
+			want: []int{9, 0, 9, 0, 0, 0, 0, 0, 0, 0},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.req.Width, tt.req.Height = 10, 1
+			grid, err := convertImageToGrid(tt.img, tt.req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(grid[0], tt.want) {
+				t.Errorf("grid = %v, want %v", grid[0], tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertImageToGridRejectsBadOptions(t *testing.T) {
+	img := grayRow(0, 255)
+	tests := []struct {
+		name string
+		req  ImageImportRequest
+	}{
+		{name: "mode", req: ImageImportRequest{Mode: "sepia"}},
+		{name: "threshold", req: ImageImportRequest{Threshold: 256}},
+		{name: "dithering in binary mode", req: ImageImportRequest{Mode: imageModeBinary, Dither: imageDitherOrdered}},
+		{name: "dilation", req: ImageImportRequest{Dilation: maxImageDilation + 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := convertImageToGrid(img, tt.req); err == nil {
+				t.Errorf("convertImageToGrid(%+v) succeeded", tt.req)
+			}
+		})
+	}
+}
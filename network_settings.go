@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+const appSettingsVersion = 1
+
+// systemCABundles are the usual locations of the system CA bundle, used when git names no
+// bundle of its own. git is pointed at a copy of its bundle plus the custom certificates,
+// because http.sslCAInfo replaces git's default bundle instead of adding to it.
+var systemCABundles = []string{
+	"/etc/ssl/certs/ca-certificates.crt", // Debian, Ubuntu, Arch
+	"/etc/pki/tls/certs/ca-bundle.crt",   // Fedora, RHEL
+	"/etc/ssl/ca-bundle.pem",             // openSUSE
+	"/etc/ssl/cert.pem",                  // macOS, Alpine, BSDs
+}
+
+// NetworkSettings route GitHub API calls and git pushes through a corporate network.
+type NetworkSettings struct {
+	// ProxyURL is an http://, https:// or socks5:// proxy. Empty uses the
+	// HTTP_PROXY/HTTPS_PROXY/NO_PROXY environment variables.
+	ProxyURL string `json:"proxyUrl,omitempty"`
+	// NoProxy lists hosts, domain suffixes (".corp.example") or CIDR ranges reached directly.
+	NoProxy []string `json:"noProxy,omitempty"`
+	// CACertFiles are PEM files trusted in addition to the system roots.
+	CACertFiles []string `json:"caCertFiles,omitempty"`
+}
+
+// networkState is what applying NetworkSettings produced. It is never modified once
+// stored, so requests and git commands in flight keep a consistent view while the
+// settings are replaced.
+type networkState struct {
+	settings    NetworkSettings
+	transport   *http.Transport
+	gitCABundle string // CA bundle passed to git as http.sslCAInfo
+}
+
+// appSettings is the app configuration persisted in settings.json.
+type appSettings struct {
+	Version int             `json:"version"`
+	Network NetworkSettings `json:"network"`
+}
+
+// GetNetworkSettings returns the proxy and certificate settings in use.
+func (a *App) GetNetworkSettings() NetworkSettings {
+	return cloneNetworkSettings(a.networkState().settings)
+}
+
+// networkState returns the network settings in use; the zero state when none were applied.
+func (a *App) networkState() *networkState {
+	if state := a.network.Load(); state != nil {
+		return state
+	}
+	return &networkState{}
+}
+
+// SetNetworkSettings validates, applies and persists proxy and certificate settings.
+func (a *App) SetNetworkSettings(settings NetworkSettings) error {
+	settings = normaliseNetworkSettings(settings)
+	if err := a.applyNetworkSettings(settings); err != nil {
+		return err
+	}
+	return saveAppSettings(&appSettings{Version: appSettingsVersion, Network: settings})
+}
+
+func normaliseNetworkSettings(settings NetworkSettings) NetworkSettings {
+	settings.ProxyURL = strings.TrimSpace(settings.ProxyURL)
+	var noProxy []string
+	for _, entry := range settings.NoProxy {
+		for _, host := range strings.Split(entry, ",") {
+			if host = strings.TrimSpace(host); host != "" {
+				noProxy = append(noProxy, host)
+			}
+		}
+	}
+	settings.NoProxy = noProxy
+	var caFiles []string
+	for _, path := range settings.CACertFiles {
+		if path = strings.TrimSpace(path); path != "" {
+			caFiles = append(caFiles, filepath.Clean(path))
+		}
+	}
+	settings.CACertFiles = caFiles
+	return settings
+}
+
+// applyNetworkSettings builds the HTTP client and git CA bundle for the settings and
+// switches to them.
+func (a *App) applyNetworkSettings(settings NetworkSettings) error {
+	state, err := a.newNetworkState(settings)
+	if err != nil {
+		return err
+	}
+	a.network.Store(state)
+	return nil
+}
+
+func (a *App) newNetworkState(settings NetworkSettings) (*networkState, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if settings.ProxyURL != "" {
+		proxy, err := url.Parse(settings.ProxyURL)
+		if err != nil || proxy.Host == "" {
+			return nil, fmt.Errorf("invalid proxy URL %q", settings.ProxyURL)
+		}
+		switch proxy.Scheme {
+		case "http", "https", "socks5", "socks5h":
+		default:
+			return nil, fmt.Errorf("unsupported proxy scheme %q (use http, https or socks5)", proxy.Scheme)
+		}
+		proxyFunc := (&httpproxy.Config{
+			HTTPProxy:  settings.ProxyURL,
+			HTTPSProxy: settings.ProxyURL,
+			NoProxy:    strings.Join(settings.NoProxy, ","),
+		}).ProxyFunc()
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			return proxyFunc(req.URL)
+		}
+	}
+
+	caBundle := ""
+	if len(settings.CACertFiles) > 0 {
+		roots, err := x509.SystemCertPool()
+		if err != nil || roots == nil {
+			roots = x509.NewCertPool()
+		}
+		var custom bytes.Buffer
+		for _, path := range settings.CACertFiles {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return nil, fmt.Errorf("read CA certificate: %w", err)
+			}
+			if !roots.AppendCertsFromPEM(data) {
+				return nil, fmt.Errorf("%s contains no PEM certificates", path)
+			}
+			custom.Write(bytes.TrimSpace(data))
+			custom.WriteByte('\n')
+		}
+		transport.TLSClientConfig = &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}
+
+		// On Windows git verifies with schannel and the Windows certificate store instead
+		// (see gitNetworkEnv), where corporate CAs are deployed.
+		if runtime.GOOS != "windows" {
+			if caBundle, err = a.writeGitCABundle(custom.Bytes()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return &networkState{settings: cloneNetworkSettings(settings), transport: transport, gitCABundle: caBundle}, nil
+}
+
+// httpClient returns a client honouring the network settings.
+func (a *App) httpClient(timeout time.Duration) *http.Client {
+	client := &http.Client{Timeout: timeout}
+	if transport := a.networkState().transport; transport != nil {
+		client.Transport = transport
+	}
+	return client
+}
+
+// gitNetworkEnv returns the environment passing the network settings to git. Settings go
+// in GIT_CONFIG_COUNT/GIT_CONFIG_KEY_n/GIT_CONFIG_VALUE_n (git 2.31+) rather than "-c"
+// options, so proxy credentials never show up on a command line; http_proxy and
+// https_proxy cover older versions.
+func (a *App) gitNetworkEnv() []string {
+	state := a.networkState()
+	var config [][2]string
+	var env []string
+	if proxy := state.settings.ProxyURL; proxy != "" {
+		config = append(config, [2]string{"http.proxy", proxy})
+		env = append(env, "http_proxy="+proxy, "https_proxy="+proxy, "HTTPS_PROXY="+proxy)
+		if len(state.settings.NoProxy) > 0 {
+			noProxy := strings.Join(state.settings.NoProxy, ",")
+			env = append(env, "no_proxy="+noProxy, "NO_PROXY="+noProxy)
+		}
+	}
+	switch {
+	case state.gitCABundle != "":
+		config = append(config, [2]string{"http.sslCAInfo", state.gitCABundle})
+	case runtime.GOOS == "windows" && len(state.settings.CACertFiles) > 0:
+		config = append(config, [2]string{"http.sslBackend", "schannel"})
+	}
+
+	if len(config) > 0 {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(config)))
+		for i, entry := range config {
+			env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, entry[0]), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, entry[1]))
+		}
+	}
+	return env
+}
+
+// gitBaseCABundle finds the CA bundle git verifies servers with: the one configured in
+// http.sslCAInfo or GIT_SSL_CAINFO, else the system bundle curl is normally built with.
+func (a *App) gitBaseCABundle() (string, error) {
+	cmd := exec.Command(a.getGitCommand(), "config", "--path", "--get", "http.sslCAInfo")
+	configureCommand(cmd, true)
+	if output, err := cmd.Output(); err == nil {
+		if path := strings.TrimSpace(string(output)); path != "" {
+			return path, nil
+		}
+	}
+	if path := strings.TrimSpace(os.Getenv("GIT_SSL_CAINFO")); path != "" {
+		return path, nil
+	}
+	for _, path := range systemCABundles {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
+		}
+	}
+	return "", fmt.Errorf("cannot find the CA bundle git uses; set http.sslCAInfo in git's configuration")
+}
+
+func (a *App) writeGitCABundle(custom []byte) (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	base, err := a.gitBaseCABundle()
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(base)
+	if err != nil {
+		return "", fmt.Errorf("read git CA bundle: %w", err)
+	}
+
+	var bundle bytes.Buffer
+	bundle.Write(data)
+	bundle.WriteByte('\n')
+	bundle.Write(custom)
+
+	path := filepath.Join(dir, "ca-bundle.pem")
+	if err := os.WriteFile(path, bundle.Bytes(), 0o644); err != nil {
+		return "", fmt.Errorf("write git CA bundle: %w", err)
+	}
+	return path, nil
+}
+
+func cloneNetworkSettings(settings NetworkSettings) NetworkSettings {
+	settings.NoProxy = append([]string(nil), settings.NoProxy...)
+	settings.CACertFiles = append([]string(nil), settings.CACertFiles...)
+	return settings
+}
+
+// loadAppSettings applies the persisted settings; missing settings keep the defaults. A CA
+// certificate that can no longer be read doesn't cost the proxy: the settings are applied
+// without the custom certificates and the error reports them.
+func (a *App) loadAppSettings() error {
+	file, err := readAppSettings()
+	if err != nil || file == nil {
+		return err
+	}
+	settings := normaliseNetworkSettings(file.Network)
+	state, err := a.newNetworkState(settings)
+	if err != nil && len(settings.CACertFiles) > 0 {
+		caErr := err
+		withoutCAs := settings
+		withoutCAs.CACertFiles = nil
+		if state, err = a.newNetworkState(withoutCAs); err == nil {
+			err = fmt.Errorf("custom CA certificates were not applied: %w", caErr)
+		}
+	}
+	if state != nil {
+		a.network.Store(state)
+	}
+	return err
+}
+
+func appSettingsPath() (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "settings.json"), nil
+}
+
+func readAppSettings() (*appSettings, error) {
+	path, err := appSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var settings appSettings
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("decode app settings: %w", err)
+	}
+	if settings.Version != appSettingsVersion {
+		return nil, fmt.Errorf("unsupported app settings version %d", settings.Version)
+	}
+	return &settings, nil
+}
+
+func saveAppSettings(settings *appSettings) error {
+	path, err := appSettingsPath()
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode app settings: %w", err)
+	}
+	return os.WriteFile(path, data, 0o600)
+}
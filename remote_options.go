@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const defaultBranchName = "main"
+
+// GitHub allows at most 20 topics per repository, greenWallTopic included.
+const maxRepoTopics = 20
+
+var githubTopicValidator = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,49}$`)
+var licenseKeyValidator = regexp.MustCompile(`^[a-z0-9][a-z0-9.-]*$`)
+
+// requestedRemoteRepo expands the name template of the request's remote repository for login
+// and normalises its options. It returns nil when no remote repository is wanted.
+func requestedRemoteRepo(req GenerateRepoRequest, login string) (*RemoteRepoOptions, error) {
+	if req.RemoteRepo == nil || !req.RemoteRepo.Enabled {
+		return nil, nil
+	}
+	options := *req.RemoteRepo
+	name, err := expandRepoNameTemplate(options.Name, login, req.Year, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	options.Name = name
+	return normaliseRemoteRepoOptions(&options)
+}
+
+// normaliseRemoteRepoOptions trims and validates the remote repository settings so that
+// every problem is reported before any local work starts.
+func normaliseRemoteRepoOptions(opts *RemoteRepoOptions) (*RemoteRepoOptions, error) {
+	name := strings.TrimSpace(opts.Name)
+	if name == "" {
+		return nil, fmt.Errorf("remote repository name cannot be empty")
+	}
+	if !githubRepoNameValidator.MatchString(name) {
+		return nil, fmt.Errorf("remote repository name may only contain letters, numbers, '.', '_' or '-'")
+	}
+
+	normalised := &RemoteRepoOptions{
+		Enabled:          true,
+		Name:             name,
+		Private:          opts.Private,
+		Description:      strings.TrimSpace(opts.Description),
+		Homepage:         strings.TrimSpace(opts.Homepage),
+		DefaultBranch:    strings.TrimSpace(opts.DefaultBranch),
+		LicenseTemplate:  strings.ToLower(strings.TrimSpace(opts.LicenseTemplate)),
+		DisableIssues:    opts.DisableIssues,
+		DisableWiki:      opts.DisableWiki,
+		DisableProjects:  opts.DisableProjects,
+		ArchiveAfterPush: opts.ArchiveAfterPush,
+	}
+
+	topics := []string{greenWallTopic}
+	for _, topic := range opts.Topics {
+		topic = strings.ToLower(strings.TrimSpace(topic))
+		if topic == "" || containsString(topics, topic) {
+			continue
+		}
+		if !githubTopicValidator.MatchString(topic) {
+			return nil, fmt.Errorf("invalid topic %q: topics must start with a letter or number and contain at most 50 lowercase letters, numbers or '-'", topic)
+		}
+		topics = append(topics, topic)
+	}
+	if len(topics) > maxRepoTopics {
+		return nil, fmt.Errorf("too many topics: GitHub allows %d, including %q", maxRepoTopics, greenWallTopic)
+	}
+	normalised.Topics = topics
+
+	if normalised.Homepage != "" {
+		parsed, err := url.Parse(normalised.Homepage)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("homepage must be an absolute http(s) URL")
+		}
+	}
+
+	if normalised.DefaultBranch == "" {
+		normalised.DefaultBranch = defaultBranchName
+	}
+	if err := validateBranchName(normalised.DefaultBranch); err != nil {
+		return nil, err
+	}
+
+	if normalised.LicenseTemplate != "" && !licenseKeyValidator.MatchString(normalised.LicenseTemplate) {
+		return nil, fmt.Errorf("invalid license template %q", normalised.LicenseTemplate)
+	}
+
+	return normalised, nil
+}
+
+// validateBranchName applies the subset of git check-ref-format rules that matter for a single branch name.
+func validateBranchName(name string) error {
+	invalid := func(reason string) error {
+		return fmt.Errorf("invalid default branch %q: %s", name, reason)
+	}
+
+	switch {
+	case name == "@":
+		return invalid("'@' is not a valid branch name")
+	case strings.HasPrefix(name, "-"), strings.HasPrefix(name, "/"):
+		return invalid("it may not start with '-' or '/'")
+	case strings.HasSuffix(name, "/"), strings.HasSuffix(name, "."), strings.HasSuffix(name, ".lock"):
+		return invalid("it may not end with '/', '.' or '.lock'")
+	case strings.Contains(name, ".."), strings.Contains(name, "//"), strings.Contains(name, "@{"):
+		return invalid("it may not contain '..', '//' or '@{'")
+	}
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(" ~^:?*[\\", r) {
+			return invalid("it contains a forbidden character")
+		}
+	}
+	for _, part := range strings.Split(name, "/") {
+		if strings.HasPrefix(part, ".") {
+			return invalid("no path component may start with '.'")
+		}
+	}
+	return nil
+}
+
+// fetchLicenseTemplate looks up a license by its SPDX-style key, e.g. "mit" or "apache-2.0".
+func (a *App) fetchLicenseTemplate(auth githubAuth, key string) (string, error) {
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodGet, "/licenses/"+url.PathEscape(key), auth.Token, nil)
+	if err != nil {
+		return "", fmt.Errorf("build GitHub license request failed: %w", err)
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return "", fmt.Errorf("fetch license template failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("unknown license template %q", key)
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("GitHub API returned error for /licenses/%s (%d): %s", key, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return "", fmt.Errorf("decode license template failed: %w", err)
+	}
+	return payload.Body, nil
+}
+
+// renderLicense fills in the placeholders GitHub itself would replace when creating a repository from a template.
+func renderLicense(body string, year int, holder string) string {
+	replacer := strings.NewReplacer(
+		"[year]", fmt.Sprint(year),
+		"[yyyy]", fmt.Sprint(year),
+		"[fullname]", holder,
+		"[name of copyright owner]", holder,
+	)
+	return replacer.Replace(body)
+}
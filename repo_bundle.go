@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+type ExportRepoBundleRequest struct {
+	RepoPath string `json:"repoPath"` // as returned by GenerateRepo
+}
+
+type ExportRepoBundleResponse struct {
+	BundlePath string   `json:"bundlePath"`
+	Size       int64    `json:"size"` // bytes
+	Refs       []string `json:"refs"` // the refs a clone of the bundle gets, e.g. refs/heads/main
+}
+
+// ExportRepoBundle writes the history of a generated repository to a single-file git bundle
+// and checks it with `git bundle verify`, for networks where the app may not push: the
+// bundle is carried elsewhere, cloned with `git clone wall.bundle` and pushed from there.
+func (a *App) ExportRepoBundle(req ExportRepoBundleRequest) (*ExportRepoBundleResponse, error) {
+	repoPath, err := a.generatedRepoPath(req.RepoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	bundlePath, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "导出 Git bundle",
+		DefaultFilename: filepath.Base(repoPath) + ".bundle",
+		Filters: []runtime.FileFilter{
+			{DisplayName: "Git bundle (*.bundle)", Pattern: "*.bundle"},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("open save file dialog: %w", err)
+	}
+	if bundlePath == "" {
+		return nil, fmt.Errorf("export cancelled")
+	}
+	if bundlePath, err = filepath.Abs(bundlePath); err != nil {
+		return nil, fmt.Errorf("resolve bundle path: %w", err)
+	}
+
+	return a.writeRepoBundle(repoPath, bundlePath)
+}
+
+// writeRepoBundle bundles HEAD and every branch of the repository, so a clone checks out
+// the generated branch, then verifies the bundle. A bundle that fails verification is removed.
+func (a *App) writeRepoBundle(repoPath, bundlePath string) (*ExportRepoBundleResponse, error) {
+	if err := a.runGitCommand(repoPath, "bundle", "create", bundlePath, "HEAD", "--branches"); err != nil {
+		return nil, err
+	}
+	if err := a.runGitCommand(repoPath, "bundle", "verify", "--quiet", bundlePath); err != nil {
+		_ = os.Remove(bundlePath)
+		return nil, fmt.Errorf("the bundle failed verification: %w", err)
+	}
+
+	refs := []string{}
+	err := a.scanGitOutput(repoPath, []string{"bundle", "list-heads", bundlePath}, func(line string) {
+		if _, ref, ok := strings.Cut(strings.TrimSpace(line), " "); ok && ref != "HEAD" {
+			refs = append(refs, ref)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(bundlePath)
+	if err != nil {
+		return nil, fmt.Errorf("stat bundle: %w", err)
+	}
+	return &ExportRepoBundleResponse{BundlePath: bundlePath, Size: info.Size(), Refs: refs}, nil
+}
+
+// generatedRepoPath checks that path is a repository GenerateRepo created, so the binding
+// can't be pointed at arbitrary directories.
+func (a *App) generatedRepoPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return "", fmt.Errorf("no repository path supplied")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("resolve repository path: %w", err)
+	}
+	base, err := filepath.Abs(a.repoBasePath)
+	if err != nil {
+		return "", fmt.Errorf("resolve repo base directory: %w", err)
+	}
+	if rel, err := filepath.Rel(base, abs); err != nil || rel == "." || rel == ".." || strings.ContainsRune(rel, filepath.Separator) {
+		return "", fmt.Errorf("%s is not a repository generated by GreenWall", path)
+	}
+	if info, err := os.Stat(filepath.Join(abs, ".git")); err != nil || !info.IsDir() {
+		return "", fmt.Errorf("%s is not a repository generated by GreenWall", path)
+	}
+	return abs, nil
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWriteRepoBundle(t *testing.T) {
+	repo := gitHistoryFixture(t, [][2]string{
+		{"2024-01-01T12:00:00Z", "2024-01-01T12:00:00Z"},
+		{"2024-01-02T12:00:00Z", "2024-01-02T12:00:00Z"},
+	})
+	git := func(dir string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	branch := git(repo, "symbolic-ref", "--short", "HEAD")
+
+	app := NewApp()
+	bundlePath := filepath.Join(t.TempDir(), "wall.bundle")
+	resp, err := app.writeRepoBundle(repo, bundlePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.BundlePath != bundlePath || resp.Size <= 0 {
+		t.Errorf("response = %+v, want the bundle's path and size", resp)
+	}
+	if len(resp.Refs) != 1 || resp.Refs[0] != "refs/heads/"+branch {
+		t.Errorf("refs = %v, want [refs/heads/%s]", resp.Refs, branch)
+	}
+
+	git(repo, "bundle", "verify", bundlePath)
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(filepath.Dir(clone), "clone", "-q", bundlePath, clone)
+	if count := git(clone, "rev-list", "--count", "HEAD"); count != "2" {
+		t.Errorf("the clone has %s commits, want 2", count)
+	}
+}
+
+func TestGeneratedRepoPath(t *testing.T) {
+	base := t.TempDir()
+	app := NewApp()
+	app.repoBasePath = base
+	generated := filepath.Join(base, "wall-123")
+	if err := os.MkdirAll(filepath.Join(generated, ".git"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(base, "plain"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := app.generatedRepoPath(generated); err != nil || got != generated {
+		t.Errorf("generatedRepoPath(%q) = %q, %v", generated, got, err)
+	}
+	for _, path := range []string{"", base, filepath.Dir(base), filepath.Join(base, "plain"), filepath.Join(generated, ".git")} {
+		if _, err := app.generatedRepoPath(path); err == nil {
+			t.Errorf("generatedRepoPath(%q) accepted a path GenerateRepo didn't create", path)
+		}
+	}
+}
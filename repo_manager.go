@@ -0,0 +1,387 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	greenWallTopic        = "greenwall"
+	greenWallManifestFile = ".greenwall.json"
+)
+
+// Actions accepted by ApplyManagedRepoAction.
+const (
+	managedRepoActionDelete     = "delete"
+	managedRepoActionArchive    = "archive"
+	managedRepoActionUnarchive  = "unarchive"
+	managedRepoActionVisibility = "visibility"
+)
+
+// greenWallManifest is committed into every generated repository so it can be
+// recognised and summarised later without walking its history.
+type greenWallManifest struct {
+	Generator   string `json:"generator"`
+	Year        int    `json:"year,omitempty"`
+	FirstDate   string `json:"firstDate"`
+	LastDate    string `json:"lastDate"`
+	CommitCount int    `json:"commitCount"`
+}
+
+type ManagedRepo struct {
+	FullName    string `json:"fullName"`
+	Owner       string `json:"owner"`
+	Name        string `json:"name"`
+	HTMLURL     string `json:"htmlUrl"`
+	Private     bool   `json:"private"`
+	Archived    bool   `json:"archived"`
+	CreatedAt   string `json:"createdAt"`
+	PushedAt    string `json:"pushedAt"`
+	Year        int    `json:"year,omitempty"`
+	FirstDate   string `json:"firstDate,omitempty"`
+	LastDate    string `json:"lastDate,omitempty"`
+	CommitCount int    `json:"commitCount,omitempty"`
+}
+
+type ManagedRepoActionRequest struct {
+	Action  string   `json:"action"`
+	Repos   []string `json:"repos"`   // full names, e.g. "octocat/octocat-2024"
+	Private bool     `json:"private"` // target visibility for the "visibility" action
+}
+
+type ManagedRepoActionResult struct {
+	Repo    string `json:"repo"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// buildGreenWallManifest renders the manifest for contributions that are already sorted by date.
+func buildGreenWallManifest(year int, contribs []ContributionDay) (string, error) {
+	manifest := greenWallManifest{
+		Generator: "https://github.com/zmrlft/GreenWall",
+		Year:      year,
+	}
+	for _, c := range contribs {
+		manifest.CommitCount += c.Count
+	}
+	if len(contribs) > 0 {
+		manifest.FirstDate = contribs[0].Date
+		manifest.LastDate = contribs[len(contribs)-1].Date
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("encode manifest: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+func (a *App) setGithubRepositoryTopics(auth githubAuth, owner, name string, topics []string) error {
+	path := fmt.Sprintf("/repos/%s/%s/topics", url.PathEscape(owner), url.PathEscape(name))
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodPut, path, auth.Token, map[string]interface{}{"names": topics})
+	if err != nil {
+		return fmt.Errorf("build GitHub topics request failed: %w", err)
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return fmt.Errorf("set GitHub repository topics failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned error for repository topics (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ListGreenWallRepos lists the signed-in user's repositories tagged with the greenwall topic,
+// including the commit range recorded in their manifest.
+func (a *App) ListGreenWallRepos() ([]ManagedRepo, error) {
+	if !a.session().signedIn() {
+		return nil, fmt.Errorf("GitHub login is required to list repositories")
+	}
+
+	repos, err := a.fetchGreenWallRepositories()
+	if err != nil {
+		return nil, err
+	}
+
+	managed := make([]ManagedRepo, 0, len(repos))
+	for _, repo := range repos {
+		entry := ManagedRepo{
+			FullName:  repo.FullName,
+			Owner:     repo.Owner.Login,
+			Name:      repo.Name,
+			HTMLURL:   repo.HTMLURL,
+			Private:   repo.Private,
+			Archived:  repo.Archived,
+			CreatedAt: repo.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+			PushedAt:  repo.PushedAt.Format("2006-01-02T15:04:05Z07:00"),
+		}
+		manifest, err := a.fetchGreenWallManifest(repo.Owner.Login, repo.Name)
+		if err != nil {
+			if a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "read manifest of %s failed: %v", repo.FullName, err)
+			}
+		} else if manifest != nil {
+			entry.Year = manifest.Year
+			entry.FirstDate = manifest.FirstDate
+			entry.LastDate = manifest.LastDate
+			entry.CommitCount = manifest.CommitCount
+		}
+		managed = append(managed, entry)
+	}
+	return managed, nil
+}
+
+// ApplyManagedRepoAction deletes, archives, unarchives or changes the visibility of
+// greenwall-tagged repositories. Repositories without the topic are never touched.
+func (a *App) ApplyManagedRepoAction(req ManagedRepoActionRequest) ([]ManagedRepoActionResult, error) {
+	if !a.session().signedIn() {
+		return nil, fmt.Errorf("GitHub login is required to manage repositories")
+	}
+	switch req.Action {
+	case managedRepoActionDelete, managedRepoActionArchive, managedRepoActionUnarchive, managedRepoActionVisibility:
+	default:
+		return nil, fmt.Errorf("unknown repository action %q", req.Action)
+	}
+	if len(req.Repos) == 0 {
+		return nil, fmt.Errorf("no repositories selected")
+	}
+
+	tagged, err := a.fetchGreenWallRepositories()
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]githubRepository, len(tagged))
+	for _, repo := range tagged {
+		known[strings.ToLower(repo.FullName)] = repo
+	}
+	for _, fullName := range req.Repos {
+		if _, ok := known[strings.ToLower(fullName)]; !ok {
+			return nil, fmt.Errorf("%s is not a repository tagged with the %s topic", fullName, greenWallTopic)
+		}
+	}
+
+	// Destructive actions are always confirmed in a native dialog the frontend can't skip.
+	if req.Action != managedRepoActionUnarchive {
+		confirmed, err := a.confirmManagedRepoAction(req)
+		if err != nil {
+			return nil, err
+		}
+		if !confirmed {
+			return nil, fmt.Errorf("repository action cancelled")
+		}
+	}
+
+	auth, err := a.accountGithubAuth("")
+	if err != nil {
+		return nil, err
+	}
+	results := make([]ManagedRepoActionResult, 0, len(req.Repos))
+	for _, fullName := range req.Repos {
+		repo := known[strings.ToLower(fullName)]
+		var err error
+		switch req.Action {
+		case managedRepoActionDelete:
+			err = a.deleteGithubRepository(auth, repo.Owner.Login, repo.Name)
+		case managedRepoActionArchive:
+			err = a.updateGithubRepository(auth, repo.Owner.Login, repo.Name, map[string]interface{}{"archived": true})
+		case managedRepoActionUnarchive:
+			err = a.updateGithubRepository(auth, repo.Owner.Login, repo.Name, map[string]interface{}{"archived": false})
+		case managedRepoActionVisibility:
+			err = a.updateGithubRepository(auth, repo.Owner.Login, repo.Name, map[string]interface{}{"private": req.Private})
+		}
+		a.auditAs(auth.AccountID, managedRepoAuditAction(req), repo.FullName, err, nil)
+		result := ManagedRepoActionResult{Repo: repo.FullName, Success: err == nil}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func managedRepoAuditAction(req ManagedRepoActionRequest) string {
+	switch req.Action {
+	case managedRepoActionDelete:
+		return auditActionRepoDelete
+	case managedRepoActionArchive:
+		return auditActionRepoArchive
+	case managedRepoActionUnarchive:
+		return auditActionRepoUnarchive
+	default:
+		return auditActionRepoVisibility
+	}
+}
+
+func (a *App) confirmManagedRepoAction(req ManagedRepoActionRequest) (bool, error) {
+	if a.ctx == nil {
+		return false, fmt.Errorf("%s requires confirmation", req.Action)
+	}
+
+	var verb string
+	switch req.Action {
+	case managedRepoActionDelete:
+		verb = "永久删除"
+	case managedRepoActionArchive:
+		verb = "归档"
+	case managedRepoActionVisibility:
+		if req.Private {
+			verb = "设为私有"
+		} else {
+			verb = "设为公开"
+		}
+	}
+
+	choice, err := runtime.MessageDialog(a.ctx, runtime.MessageDialogOptions{
+		Type:          runtime.QuestionDialog,
+		Title:         "确认仓库操作",
+		Message:       fmt.Sprintf("确定要%s以下 %d 个仓库吗？\n\n%s", verb, len(req.Repos), strings.Join(req.Repos, "\n")),
+		Buttons:       []string{"Yes", "No"},
+		DefaultButton: "No",
+		CancelButton:  "No",
+	})
+	if err != nil {
+		return false, fmt.Errorf("open confirmation dialog: %w", err)
+	}
+	return choice == "Yes", nil
+}
+
+// fetchGreenWallRepositories lists the greenwall-tagged repositories of the signed-in user,
+// or those an app installation can access: installation tokens get 403 from /user/repos.
+func (a *App) fetchGreenWallRepositories() ([]githubRepository, error) {
+	endpoint := "/user/repos?affiliation=owner&per_page=100&page=%d"
+	if a.session().App != nil {
+		endpoint = "/installation/repositories?per_page=100&page=%d"
+	}
+
+	var tagged []githubRepository
+	for page := 1; ; page++ {
+		token, err := a.activeGithubToken()
+		if err != nil {
+			return nil, err
+		}
+		path := fmt.Sprintf(endpoint, page)
+		req, err := a.newGithubRequest(http.MethodGet, path, token, nil)
+		if err != nil {
+			return nil, fmt.Errorf("build GitHub repository list request failed: %w", err)
+		}
+
+		resp, err := a.doGithubRequest(req)
+		if err != nil {
+			return nil, fmt.Errorf("list GitHub repositories failed: %w", err)
+		}
+		if resp.StatusCode >= 400 {
+			body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			resp.Body.Close()
+			return nil, fmt.Errorf("GitHub API returned error for %s (%d): %s", req.URL.Path, resp.StatusCode, strings.TrimSpace(string(body)))
+		}
+
+		repos, err := decodeRepositoryPage(resp.Body, strings.HasPrefix(path, "/installation/"))
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode GitHub repository list failed: %w", err)
+		}
+
+		for _, repo := range repos {
+			if containsString(repo.Topics, greenWallTopic) {
+				tagged = append(tagged, repo)
+			}
+		}
+		if len(repos) < 100 {
+			return tagged, nil
+		}
+	}
+}
+
+// decodeRepositoryPage reads a page of /user/repos, a plain array, or of
+// /installation/repositories, which wraps the array in an object.
+func decodeRepositoryPage(body io.Reader, installation bool) ([]githubRepository, error) {
+	if !installation {
+		var repos []githubRepository
+		err := json.NewDecoder(body).Decode(&repos)
+		return repos, err
+	}
+	var page struct {
+		Repositories []githubRepository `json:"repositories"`
+	}
+	err := json.NewDecoder(body).Decode(&page)
+	return page.Repositories, err
+}
+
+func (a *App) fetchGreenWallManifest(owner, name string) (*greenWallManifest, error) {
+	token, err := a.activeGithubToken()
+	if err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/repos/%s/%s/contents/%s", url.PathEscape(owner), url.PathEscape(name), greenWallManifestFile)
+	req, err := a.newGithubRequest(http.MethodGet, path, token, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("GitHub API returned error for %s (%d): %s", greenWallManifestFile, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var payload struct {
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("decode manifest response: %w", err)
+	}
+	if payload.Encoding != "base64" {
+		return nil, fmt.Errorf("unexpected manifest encoding %q", payload.Encoding)
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(payload.Content, "\n", ""))
+	if err != nil {
+		return nil, fmt.Errorf("decode manifest content: %w", err)
+	}
+
+	var manifest greenWallManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+func (a *App) updateGithubRepository(auth githubAuth, owner, name string, changes map[string]interface{}) error {
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(name))
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodPatch, path, auth.Token, changes)
+	if err != nil {
+		return fmt.Errorf("build GitHub repository update request failed: %w", err)
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return fmt.Errorf("update GitHub repository failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("GitHub API returned error for repository update (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
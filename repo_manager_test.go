@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// newManagedRepoServer lists acme/wall with the greenwall topic and acme/other without it,
+// and records every other request.
+func newManagedRepoServer(t *testing.T) (*App, *[]string) {
+	t.Helper()
+	var requests []string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/user/repos" {
+			fmt.Fprint(w, `[
+				{"name":"wall","full_name":"acme/wall","topics":["greenwall"],"owner":{"login":"acme"}},
+				{"name":"other","full_name":"acme/other","topics":["art"],"owner":{"login":"acme"}}
+			]`)
+			return
+		}
+		var body map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&body)
+		requests = append(requests, fmt.Sprintf("%s %s %v", r.Method, r.URL.Path, body))
+		w.WriteHeader(http.StatusOK)
+	}))
+	acc := &githubAccount{ID: "github.com/acme", Host: defaultGithubHost, User: &GithubUserProfile{Login: "acme"}, token: "gho_acme"}
+	app.accounts = []*githubAccount{acc}
+	app.activeAccountID = acc.ID
+	return app, &requests
+}
+
+func TestApplyManagedRepoAction(t *testing.T) {
+	tests := []struct {
+		name         string
+		req          ManagedRepoActionRequest
+		wantErr      string
+		wantRequests []string
+	}{
+		{
+			name:         "unarchive a tagged repository",
+			req:          ManagedRepoActionRequest{Action: managedRepoActionUnarchive, Repos: []string{"ACME/wall"}},
+			wantRequests: []string{"PATCH /repos/acme/wall map[archived:false]"},
+		},
+		{
+			name:    "untagged repositories are refused",
+			req:     ManagedRepoActionRequest{Action: managedRepoActionUnarchive, Repos: []string{"acme/wall", "acme/other"}},
+			wantErr: "acme/other is not a repository tagged with the greenwall topic",
+		},
+		{
+			name:    "deleting needs confirmation",
+			req:     ManagedRepoActionRequest{Action: managedRepoActionDelete, Repos: []string{"acme/wall"}},
+			wantErr: "requires confirmation",
+		},
+		{
+			name:    "changing visibility needs confirmation",
+			req:     ManagedRepoActionRequest{Action: managedRepoActionVisibility, Repos: []string{"acme/wall"}, Private: true},
+			wantErr: "requires confirmation",
+		},
+		{
+			name:    "unknown action",
+			req:     ManagedRepoActionRequest{Action: "rename", Repos: []string{"acme/wall"}},
+			wantErr: "unknown repository action",
+		},
+		{
+			name:    "nothing selected",
+			req:     ManagedRepoActionRequest{Action: managedRepoActionArchive},
+			wantErr: "no repositories selected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, requests := newManagedRepoServer(t)
+			results, err := app.ApplyManagedRepoAction(tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			} else if len(results) != 1 || !results[0].Success {
+				t.Errorf("results = %+v", results)
+			}
+			if strings.Join(*requests, "\n") != strings.Join(tt.wantRequests, "\n") {
+				t.Errorf("requests = %q, want %q", *requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestDeleteGithubRepositoryNotFound(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	err := app.deleteGithubRepository(githubAuth{Host: defaultGithubHost, Token: "gho_acme"}, "acme", "wall")
+	if !errors.Is(err, errGithubRepoNotFound) || !strings.Contains(err.Error(), "acme/wall was not found or not accessible") {
+		t.Fatalf("error = %v, want it reported as not found or not accessible", err)
+	}
+}
+
+func TestPushPendingGenerationRetriesTagging(t *testing.T) {
+	repoPath := gitHistoryFixture(t, [][2]string{{"2024-01-01T12:00:00Z", "2024-01-01T12:00:00Z"}})
+	branch, err := exec.Command("git", "-C", repoPath, "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := t.TempDir()
+	if out, err := exec.Command("git", "init", "-q", "--bare", remote).CombinedOutput(); err != nil {
+		t.Fatalf("%v\n%s", err, out)
+	}
+
+	var tagCalls int32
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || r.URL.Path != "/repos/octocat/wall/topics" {
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+		if atomic.AddInt32(&tagCalls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	pending := &PendingGeneration{
+		AccountID:   "github.com/octocat",
+		RepoPath:    repoPath,
+		CommitCount: 1,
+		Owner:       "octocat",
+		RepoName:    "wall",
+		CloneURL:    remote,
+		Branch:      strings.TrimSpace(string(branch)),
+		Topics:      []string{greenWallTopic},
+		Steps:       []string{generationStepLocalRepo, generationStepHistory, generationStepRemoteRequested, generationStepRemoteRepo},
+	}
+	auth := githubAuth{AccountID: "github.com/octocat", Host: defaultGithubHost, Token: "gho_octocat"}
+
+	if _, err := app.pushPendingGeneration(pending, auth); !errors.Is(err, errGenerationPending) {
+		t.Fatalf("error = %v, want the generation kept pending", err)
+	}
+	journal, err := app.loadPendingGeneration()
+	if err != nil || journal == nil {
+		t.Fatalf("journal = %v, %v", journal, err)
+	}
+	if !containsString(journal.Steps, generationStepPushed) || containsString(journal.Steps, generationStepTagged) {
+		t.Errorf("steps = %v, want pushed but not tagged", journal.Steps)
+	}
+	if out, err := exec.Command("git", "-C", remote, "rev-parse", "--verify", journal.Branch).CombinedOutput(); err != nil {
+		t.Errorf("the branch was not pushed: %v\n%s", err, out)
+	}
+
+	if _, err := app.pushPendingGeneration(journal, auth); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&tagCalls); n != 2 {
+		t.Errorf("tagged %d times, want 2", n)
+	}
+	if journal, err := app.loadPendingGeneration(); err != nil || journal != nil {
+		t.Errorf("journal = %v, %v, want it cleared", journal, err)
+	}
+}
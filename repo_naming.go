@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var repoNamePlaceholder = regexp.MustCompile(`\{([a-z]+)\}`)
+
+// Limits on the alternatives offered for a taken name and the lookups spent finding them.
+const (
+	maxRepoNameSuggestions = 3
+	maxRepoNameProbes      = 10
+)
+
+// maxRepoNameLength is the longest repository name GitHub accepts.
+const maxRepoNameLength = 64
+
+type RepoNameCheckRequest struct {
+	Template string `json:"template"` // a plain name or a template such as "{login}-{year}-wall"
+	Year     int    `json:"year"`
+}
+
+type RepoNameCheckResponse struct {
+	Name        string   `json:"name"`
+	Available   bool     `json:"available"`
+	Suggestions []string `json:"suggestions,omitempty"`
+}
+
+// CheckRepoName expands a repository name template and checks whether the signed-in
+// user can still create a repository with that name, suggesting free alternatives if not.
+func (a *App) CheckRepoName(req RepoNameCheckRequest) (*RepoNameCheckResponse, error) {
+	session := a.session()
+	if !session.signedIn() {
+		return nil, fmt.Errorf("GitHub login is required to check repository names")
+	}
+
+	name, err := expandRepoNameTemplate(req.Template, session.User.Login, req.Year, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	auth, err := a.accountGithubAuth(session.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	available, err := a.githubRepoNameAvailable(auth, session.User.Login, name)
+	if err != nil {
+		return nil, err
+	}
+	resp := &RepoNameCheckResponse{Name: name, Available: available}
+	if !available {
+		if resp.Suggestions, err = a.suggestRepoNames(auth, session.User.Login, name, req.Year); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+// expandRepoNameTemplate replaces {login}, {year} and {date} placeholders and
+// sanitises the result into a valid GitHub repository name.
+func expandRepoNameTemplate(template, login string, year int, now time.Time) (string, error) {
+	template = strings.TrimSpace(template)
+	if template == "" {
+		return "", fmt.Errorf("remote repository name cannot be empty")
+	}
+	if year <= 0 {
+		year = now.Year()
+	}
+
+	var unknown string
+	expanded := repoNamePlaceholder.ReplaceAllStringFunc(template, func(match string) string {
+		switch key := match[1 : len(match)-1]; key {
+		case "login":
+			return login
+		case "year":
+			return fmt.Sprint(year)
+		case "date":
+			return now.Format("20060102")
+		default:
+			if unknown == "" {
+				unknown = match
+			}
+			return match
+		}
+	})
+	if unknown != "" {
+		return "", fmt.Errorf("unknown placeholder %s in repository name template (supported: {login}, {year}, {date})", unknown)
+	}
+
+	// Plain names are validated as typed; only names produced from placeholders are sanitised.
+	name := expanded
+	if expanded != template {
+		name = sanitiseRepoName(expanded)
+	}
+	if !githubRepoNameValidator.MatchString(name) {
+		return "", fmt.Errorf("remote repository name may only contain letters, numbers, '.', '_' or '-'")
+	}
+	return name, nil
+}
+
+func (a *App) githubRepoNameAvailable(auth githubAuth, owner, name string) (bool, error) {
+	exists, err := a.githubRepositoryExists(auth, owner, name)
+	return !exists, err
+}
+
+// githubRepositoryExists looks the repository up with auth; one the token can't see counts
+// as missing.
+func (a *App) githubRepositoryExists(auth githubAuth, owner, name string) (bool, error) {
+	path := fmt.Sprintf("/repos/%s/%s", url.PathEscape(owner), url.PathEscape(name))
+	req, err := a.newGithubRequestForHost(auth.Host, http.MethodGet, path, auth.Token, nil)
+	if err != nil {
+		return false, fmt.Errorf("build GitHub repository lookup request failed: %w", err)
+	}
+
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return false, fmt.Errorf("look up GitHub repository failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, nil
+	case resp.StatusCode < 300:
+		return true, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return false, fmt.Errorf("GitHub API returned error for repository lookup (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+}
+
+func (a *App) suggestRepoNames(auth githubAuth, owner, taken string, year int) ([]string, error) {
+	var candidates []string
+	if year > 0 && !strings.Contains(taken, fmt.Sprint(year)) {
+		candidates = append(candidates, repoNameWithSuffix(taken, fmt.Sprintf("-%d", year)))
+	}
+	for i := 2; len(candidates) < maxRepoNameProbes; i++ {
+		candidates = append(candidates, repoNameWithSuffix(taken, fmt.Sprintf("-%d", i)))
+	}
+
+	var suggestions []string
+	for _, candidate := range candidates {
+		candidate = sanitiseRepoName(candidate)
+		if !githubRepoNameValidator.MatchString(candidate) || strings.EqualFold(candidate, taken) || containsString(suggestions, candidate) {
+			continue
+		}
+		available, err := a.githubRepoNameAvailable(auth, owner, candidate)
+		if err != nil {
+			return nil, err
+		}
+		if available {
+			suggestions = append(suggestions, candidate)
+			if len(suggestions) == maxRepoNameSuggestions {
+				break
+			}
+		}
+	}
+	return suggestions, nil
+}
+
+// repoNameWithSuffix appends suffix to name, shortening name first so the suffix survives
+// the length limit.
+func repoNameWithSuffix(name, suffix string) string {
+	if keep := maxRepoNameLength - len(suffix); len(name) > keep {
+		name = name[:keep]
+	}
+	return name + suffix
+}
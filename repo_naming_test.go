@@ -0,0 +1,77 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestExpandRepoNameTemplate(t *testing.T) {
+	now := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		template string
+		login    string
+		year     int
+		want     string
+		wantErr  string
+	}{
+		{name: "plain name", template: " my-wall ", want: "my-wall"},
+		{name: "plain names are not sanitised", template: "my wall", wantErr: "may only contain"},
+		{name: "empty", template: "  ", wantErr: "cannot be empty"},
+		{name: "placeholders", template: "{login}-{year}-{date}", login: "octocat", year: 2024, want: "octocat-2024-20250309"},
+		{name: "year defaults to now", template: "wall-{year}", want: "wall-2025"},
+		{name: "sanitised", template: "{login}'s wall!", login: "Octo Cat", want: "Octo-Cat-s-wall"},
+		{name: "edges trimmed", template: "--{login}--", login: "octocat", want: "octocat"},
+		{name: "unknown placeholder", template: "{user}-{year}", wantErr: "unknown placeholder {user}"},
+		{name: "over-long result", template: "{login}-wall", login: strings.Repeat("a", 70), want: strings.Repeat("a", maxRepoNameLength)},
+		{name: "nothing left", template: "{login}", login: "!!!", wantErr: "may only contain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := expandRepoNameTemplate(tt.template, tt.login, tt.year, now)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("expandRepoNameTemplate(%q) = %q, %v, want an error containing %q", tt.template, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("expandRepoNameTemplate(%q) = %q, %v, want %q", tt.template, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSuggestRepoNamesForLongNames(t *testing.T) {
+	taken := strings.Repeat("w", 62)
+	var mu sync.Mutex
+	var probed []string
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/repos/octocat/")
+		mu.Lock()
+		probed = append(probed, name)
+		mu.Unlock()
+		if name == taken {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	auth := githubAuth{AccountID: "github.com/octocat", Host: defaultGithubHost, Token: "gho_octocat"}
+	suggestions, err := app.suggestRepoNames(auth, "octocat", taken, 2024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{taken[:59] + "-2024", taken[:62] + "-2", taken[:62] + "-3"}
+	if strings.Join(suggestions, ",") != strings.Join(want, ",") {
+		t.Errorf("suggestions = %q, want %q", suggestions, want)
+	}
+	for _, name := range probed {
+		if name == taken || len(name) > maxRepoNameLength {
+			t.Errorf("probed %q", name)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	githubSSORequiredEvent = "github:sso-required"
+	githubSSOHeader        = "X-GitHub-SSO"
+)
+
+// SSORequiredError is returned when GitHub refuses a request because the token has not
+// been authorized for an organization that enforces SAML single sign-on. Once the user
+// has visited AuthorizationURL the same operation can simply be retried.
+type SSORequiredError struct {
+	AuthorizationURL string `json:"authorizationUrl"`
+	Method           string `json:"method"`
+	Path             string `json:"path"`
+}
+
+func (e *SSORequiredError) Error() string {
+	if e.AuthorizationURL == "" {
+		return fmt.Sprintf("GitHub requires SAML single sign-on authorization of the token for %s %s; authorize it for the organization and try again", e.Method, e.Path)
+	}
+	return fmt.Sprintf("GitHub requires SAML single sign-on authorization of the token for %s %s; authorize it at %s and try again", e.Method, e.Path, e.AuthorizationURL)
+}
+
+// parseSSOHeader reads "required; url=https://github.com/orgs/acme/sso?authorization_request=…"
+// and "partial-results; organizations=21955855,20582480".
+func parseSSOHeader(header string) (status string, params map[string]string) {
+	params = map[string]string{}
+	for i, part := range strings.Split(header, ";") {
+		part = strings.TrimSpace(part)
+		if i == 0 {
+			status = strings.ToLower(part)
+			continue
+		}
+		if key, value, ok := strings.Cut(part, "="); ok {
+			params[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return status, params
+}
+
+// checkSSOResponse turns a 403 carrying X-GitHub-SSO into an SSORequiredError and notifies
+// the frontend. Responses listing partial results only get logged.
+func (a *App) checkSSOResponse(req *http.Request, resp *http.Response) error {
+	header := resp.Header.Get(githubSSOHeader)
+	if header == "" {
+		return nil
+	}
+
+	status, params := parseSSOHeader(header)
+	switch {
+	case status == "required" && resp.StatusCode == http.StatusForbidden:
+		ssoErr := &SSORequiredError{AuthorizationURL: params["url"], Method: req.Method, Path: req.URL.Path}
+		if a.ctx != nil {
+			runtime.EventsEmit(a.ctx, githubSSORequiredEvent, ssoErr)
+		}
+		return ssoErr
+	case status == "partial-results":
+		if a.ctx != nil {
+			runtime.LogWarningf(a.ctx, "GitHub omitted results from SSO-protected organizations %s for %s; authorize the token for them to include them", params["organizations"], req.URL.Path)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseSSOHeader(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		wantStatus string
+		wantParams map[string]string
+	}{
+		{
+			name:       "required",
+			header:     "required; url=https://github.com/orgs/acme/sso?authorization_request=AZSC",
+			wantStatus: "required",
+			wantParams: map[string]string{"url": "https://github.com/orgs/acme/sso?authorization_request=AZSC"},
+		},
+		{
+			name:       "partial results",
+			header:     "partial-results; organizations=21955855,20582480",
+			wantStatus: "partial-results",
+			wantParams: map[string]string{"organizations": "21955855,20582480"},
+		},
+		{
+			name:       "case and spacing",
+			header:     "  Required ;URL = https://github.com/orgs/acme/sso ",
+			wantStatus: "required",
+			wantParams: map[string]string{"url": "https://github.com/orgs/acme/sso"},
+		},
+		{name: "status only", header: "required", wantStatus: "required", wantParams: map[string]string{}},
+		{name: "parameter without value", header: "required; url", wantStatus: "required", wantParams: map[string]string{}},
+		{name: "empty", header: "", wantStatus: "", wantParams: map[string]string{}},
+		{name: "only separators", header: ";;", wantStatus: "", wantParams: map[string]string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, params := parseSSOHeader(tt.header)
+			if status != tt.wantStatus || !reflect.DeepEqual(params, tt.wantParams) {
+				t.Errorf("parseSSOHeader(%q) = %q, %v, want %q, %v", tt.header, status, params, tt.wantStatus, tt.wantParams)
+			}
+		})
+	}
+}
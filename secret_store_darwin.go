@@ -0,0 +1,58 @@
+//go:build darwin
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// The macOS keychain is driven through /usr/bin/security.
+
+func keyringAvailable() bool {
+	_, err := exec.LookPath("security")
+	return err == nil
+}
+
+func keyringSet(account, secret string) error {
+	// Feed the command through interactive mode so the secret never shows up in the process list.
+	cmd := exec.Command("security", "-i")
+	cmd.Stdin = strings.NewReader(fmt.Sprintf("add-generic-password -U -s %s -a %s -w %s\n",
+		quoteSecurityArg(keyringService), quoteSecurityArg(account), quoteSecurityArg(secret)))
+	return runSecurity(cmd)
+}
+
+func keyringGet(account string) (string, error) {
+	cmd := exec.Command("security", "find-generic-password", "-s", keyringService, "-a", account, "-w")
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := runSecurity(cmd); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+func keyringDelete(account string) error {
+	return runSecurity(exec.Command("security", "delete-generic-password", "-s", keyringService, "-a", account))
+}
+
+func runSecurity(cmd *exec.Cmd) error {
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		// errSecItemNotFound
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 44 {
+			return errKeyringItemAbsent
+		}
+		return fmt.Errorf("security: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+func quoteSecurityArg(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
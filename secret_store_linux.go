@@ -0,0 +1,76 @@
+//go:build linux
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// The freedesktop Secret Service is driven through libsecret's secret-tool, the same
+// way git and the file explorer are driven through their command line tools.
+
+var keyringProbe struct {
+	once      sync.Once
+	available bool
+}
+
+// keyringAvailable reports whether secret-tool is installed and a Secret Service answers it;
+// headless systems often have the tool but no service. The probe runs once per start.
+func keyringAvailable() bool {
+	keyringProbe.once.Do(func() {
+		if _, err := exec.LookPath("secret-tool"); err != nil {
+			return
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		err := runSecretTool(exec.CommandContext(ctx, "secret-tool", "search", "service", keyringService, "account", "probe"))
+		keyringProbe.available = err == nil || errors.Is(err, errKeyringItemAbsent)
+	})
+	return keyringProbe.available
+}
+
+func keyringSet(account, secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label=GreenWall GitHub token", "service", keyringService, "account", account)
+	cmd.Stdin = strings.NewReader(secret)
+	return runSecretTool(cmd)
+}
+
+func keyringGet(account string) (string, error) {
+	cmd := exec.Command("secret-tool", "lookup", "service", keyringService, "account", account)
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := runSecretTool(cmd); err != nil {
+		return "", err
+	}
+	secret := strings.TrimSpace(stdout.String())
+	if secret == "" {
+		return "", errKeyringItemAbsent
+	}
+	return secret, nil
+}
+
+func keyringDelete(account string) error {
+	return runSecretTool(exec.Command("secret-tool", "clear", "service", keyringService, "account", account))
+}
+
+func runSecretTool(cmd *exec.Cmd) error {
+	configureCommand(cmd, true)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		// secret-tool exits with 1 and no message when nothing matches.
+		if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 && stderr.Len() == 0 {
+			return errKeyringItemAbsent
+		}
+		return fmt.Errorf("secret-tool: %w (%s)", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,11 @@
+//go:build !linux && !darwin && !windows
+
+package main
+
+func keyringAvailable() bool { return false }
+
+func keyringSet(account, secret string) error { return errKeyringItemAbsent }
+
+func keyringGet(account string) (string, error) { return "", errKeyringItemAbsent }
+
+func keyringDelete(account string) error { return errKeyringItemAbsent }
@@ -0,0 +1,200 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	// offlineRetryInterval is how often unverified sessions are retried while GitHub is unreachable.
+	offlineRetryInterval = 30 * time.Second
+	maxAvatarBytes       = 1 << 20
+)
+
+// Why an account's cached profile could not be verified.
+const (
+	unverifiedOffline     = "offline"      // GitHub could not be reached
+	unverifiedSSORequired = "sso-required" // the token isn't authorized for an organization's SAML SSO
+	unverifiedServerError = "server-error" // GitHub answered 5xx
+	unverifiedError       = "error"        // any other unexpected answer
+)
+
+// errGithubUnavailable marks 5xx answers: GitHub is reachable but failing, which says
+// nothing about the token.
+var errGithubUnavailable = errors.New("GitHub is unavailable")
+
+// unverifiedReason classifies a failed check that did not reject the token.
+func unverifiedReason(err error) string {
+	var ssoErr *SSORequiredError
+	var urlErr *url.Error
+	switch {
+	case errors.As(err, &ssoErr):
+		return unverifiedSSORequired
+	case errors.Is(err, errGithubUnavailable):
+		return unverifiedServerError
+	case errors.As(err, &urlErr):
+		return unverifiedOffline
+	default:
+		return unverifiedError
+	}
+}
+
+// verifyAccount checks the account's token against GitHub. Only a 401 says anything about
+// the token, and marks the account expired. Any other failure keeps the cached profile and
+// leaves the account unverified, with unverifiedReason's explanation, until
+// revalidateGithubTokens gets through. A user token verified for the first time also has
+// its permissions inspected from the same /user response.
+// The lookup runs without a.mu; its outcome is applied under it, and dropped if the token was
+// replaced meanwhile. wasUnverified is the account's state before the check, read in the same
+// critical section.
+func (a *App) verifyAccount(acc *githubAccount) (wasUnverified bool, err error) {
+	a.mu.Lock()
+	isApp, token := acc.isApp(), acc.token
+	inspect := acc.permissions == nil
+	a.mu.Unlock()
+
+	var user *GithubUserProfile
+	var permissions *TokenPermissions
+	if isApp {
+		// Installation tokens can't read /user; getting a new one proves the key still works.
+		err = a.refreshInstallationToken(acc)
+	} else {
+		var header http.Header
+		user, header, err = a.fetchGithubUser(acc.Host, token)
+		if err == nil && inspect {
+			permissions = a.tokenPermissions(acc.Host, token, header)
+		}
+	}
+	var avatarData string
+	if err != nil && !errors.Is(err, errTokenRejected) {
+		avatarData, _ = loadCachedAvatar(acc.ID)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	// Installation tokens are replaced by the check itself.
+	if !isApp && acc.token != token {
+		return false, err
+	}
+	wasUnverified = acc.unverified != ""
+	switch {
+	case err == nil:
+		if user != nil {
+			acc.User = user
+		}
+		if permissions != nil {
+			acc.permissions = permissions
+		}
+		acc.Expired = false
+		acc.unverified = ""
+		acc.avatarData = ""
+		if acc.User != nil {
+			go a.cacheAvatar(acc.ID, acc.User.AvatarURL)
+		}
+		return wasUnverified, nil
+	case errors.Is(err, errTokenRejected):
+		acc.Expired = true
+		acc.unverified = ""
+		return wasUnverified, err
+	case acc.User != nil:
+		acc.unverified = unverifiedReason(err)
+		if avatarData != "" {
+			acc.avatarData = avatarData
+		}
+		return wasUnverified, err
+	default:
+		return wasUnverified, err
+	}
+}
+
+// accountState reports whether GitHub rejected the account's token and whether the
+// account is still waiting to be verified.
+func (a *App) accountState(acc *githubAccount) (expired, unverified bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return acc.Expired, acc.unverified != ""
+}
+
+// profile is the user shown for the account; unverified accounts use the cached avatar
+// because the remote one can't be loaded offline.
+func (acc *githubAccount) profile() *GithubUserProfile {
+	user := cloneGithubUser(acc.User)
+	if user != nil && acc.unverified != "" && acc.avatarData != "" {
+		user.AvatarURL = acc.avatarData
+	}
+	return user
+}
+
+func avatarCachePath(accountID string) (string, error) {
+	dir, err := appConfigDir()
+	if err != nil {
+		return "", err
+	}
+	dir = filepath.Join(dir, "avatars")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256([]byte(accountID))
+	return filepath.Join(dir, hex.EncodeToString(sum[:8])), nil
+}
+
+// cacheAvatar stores the account's avatar so it can still be shown offline.
+func (a *App) cacheAvatar(accountID, avatarURL string) {
+	if avatarURL == "" {
+		return
+	}
+	if err := a.downloadAvatar(accountID, avatarURL); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "cache GitHub avatar failed: %v", err)
+	}
+}
+
+func (a *App) downloadAvatar(accountID, avatarURL string) error {
+	resp, err := a.httpClient(10 * time.Second).Get(avatarURL)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("avatar download returned %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxAvatarBytes))
+	if err != nil {
+		return err
+	}
+	path, err := avatarCachePath(accountID)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// loadCachedAvatar returns the cached avatar as a data URL.
+func loadCachedAvatar(accountID string) (string, error) {
+	path, err := avatarCachePath(accountID)
+	if err != nil {
+		return "", err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return "data:" + http.DetectContentType(data) + ";base64," + base64.StdEncoding.EncodeToString(data), nil
+}
+
+func removeCachedAvatar(accountID string) {
+	if path, err := avatarCachePath(accountID); err == nil {
+		_ = os.Remove(path)
+	}
+}
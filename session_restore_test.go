@@ -0,0 +1,90 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestVerifyAccountStates(t *testing.T) {
+	tests := []struct {
+		name           string
+		respond        func(w http.ResponseWriter)
+		closed         bool
+		wantExpired    bool
+		wantUnverified string
+		wantErr        bool
+	}{
+		{
+			name: "verified",
+			respond: func(w http.ResponseWriter) {
+				w.Write([]byte(`{"id":1,"login":"octocat","email":"octocat@example.com"}`))
+			},
+		},
+		{
+			name:        "rejected",
+			respond:     func(w http.ResponseWriter) { w.WriteHeader(http.StatusUnauthorized) },
+			wantExpired: true,
+			wantErr:     true,
+		},
+		{
+			name: "sso required",
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set(githubSSOHeader, "required; url=https://github.com/orgs/acme/sso?authorization_request=1")
+				w.WriteHeader(http.StatusForbidden)
+			},
+			wantUnverified: unverifiedSSORequired,
+			wantErr:        true,
+		},
+		{
+			name:           "server error",
+			respond:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusServiceUnavailable) },
+			wantUnverified: unverifiedServerError,
+			wantErr:        true,
+		},
+		{
+			name:           "unexpected answer",
+			respond:        func(w http.ResponseWriter) { w.WriteHeader(http.StatusNotFound) },
+			wantUnverified: unverifiedError,
+			wantErr:        true,
+		},
+		{
+			name:           "offline",
+			closed:         true,
+			wantUnverified: unverifiedOffline,
+			wantErr:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app, server := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				tt.respond(w)
+			}))
+			if tt.closed {
+				server.Close()
+			}
+			acc := &githubAccount{
+				ID:         githubAccountID(defaultGithubHost, "octocat"),
+				Host:       defaultGithubHost,
+				User:       &GithubUserProfile{Login: "octocat"},
+				token:      "ghp_test",
+				unverified: unverifiedOffline,
+			}
+			app.accounts = []*githubAccount{acc}
+
+			wasUnverified, err := app.verifyAccount(acc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyAccount() error = %v, want error %v", err, tt.wantErr)
+			}
+			if !wasUnverified {
+				t.Errorf("verifyAccount() wasUnverified = false, want true")
+			}
+			if acc.Expired != tt.wantExpired {
+				t.Errorf("Expired = %v, want %v", acc.Expired, tt.wantExpired)
+			}
+			if acc.unverified != tt.wantUnverified {
+				t.Errorf("unverified = %q, want %q", acc.unverified, tt.wantUnverified)
+			}
+		})
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+)
+
+// Colour modes of the terminal preview.
+const (
+	terminalColorNone      = "none" // plain ASCII, for logs, pipes and test assertions
+	terminalColor256       = "256"
+	terminalColorTrueColor = "truecolor"
+)
+
+// terminalASCIILevels stand in for the shades without colour.
+var terminalASCIILevels = [5]byte{'.', ':', 'o', 'O', '#'}
+
+// terminalLegendRanges are the commit counts of each shade, as contributionLevel assigns them.
+var terminalLegendRanges = [5]string{"0", "1-2", "3-5", "6-8", "9+"}
+
+// xterm256Steps are the channel values of the 6×6×6 colour cube.
+var xterm256Steps = [6]int{0, 95, 135, 175, 215, 255}
+
+type terminalPreviewOptions struct {
+	Color string        // none, 256 or truecolor
+	Theme CalendarTheme // colours of the shades; only the levels are used
+}
+
+// renderTerminalCalendar draws the calendar as seven rows of text with month markers above
+// and a legend below. Without colour the output is plain ASCII with no trailing spaces.
+func renderTerminalCalendar(layout *calendarLayout, opts terminalPreviewOptions) string {
+	cells := make([][]string, 7)
+	for row := range cells {
+		cells[row] = make([]string, layout.weeks)
+		for column := range cells[row] {
+			cells[row][column] = " "
+		}
+	}
+	layout.each(func(date time.Time, count int) {
+		column, row := layout.position(date)
+		cells[row][column] = terminalCell(contributionLevel(count), opts)
+	})
+
+	var out strings.Builder
+	out.WriteString(strings.TrimRight("    "+terminalMonthMarkers(layout), " "))
+	out.WriteByte('\n')
+	for row := 0; row < 7; row++ {
+		label := calendarWeekdayLabels[row]
+		line := fmt.Sprintf("%-3s %s", label, strings.Join(cells[row], " "))
+		out.WriteString(strings.TrimRight(line, " "))
+		out.WriteByte('\n')
+	}
+
+	out.WriteString("    Less")
+	for level := range terminalLegendRanges {
+		fmt.Fprintf(&out, " %s %s", terminalCell(level, opts), terminalLegendRanges[level])
+	}
+	out.WriteString(" More\n")
+	return out.String()
+}
+
+// terminalMonthMarkers places each month label over its first week; every week is two
+// characters wide.
+func terminalMonthMarkers(layout *calendarLayout) string {
+	line := []byte(strings.Repeat(" ", layout.weeks*2+3))
+	end := 0
+	for _, label := range layout.monthLabels() {
+		start := label.Column * 2
+		if start < end {
+			continue
+		}
+		copy(line[start:], label.Name)
+		end = start + len(label.Name) + 1
+	}
+	return string(line)
+}
+
+func terminalCell(level int, opts terminalPreviewOptions) string {
+	switch opts.Color {
+	case terminalColorTrueColor:
+		c := parseHexColor(opts.Theme.Levels[level])
+		return fmt.Sprintf("\x1b[38;2;%d;%d;%dm■\x1b[0m", c.R, c.G, c.B)
+	case terminalColor256:
+		c := parseHexColor(opts.Theme.Levels[level])
+		return fmt.Sprintf("\x1b[38;5;%dm■\x1b[0m", xterm256Color(int(c.R), int(c.G), int(c.B)))
+	default:
+		return string(terminalASCIILevels[level])
+	}
+}
+
+// xterm256Color returns the palette index closest to the colour, from the colour cube or
+// the grey ramp.
+func xterm256Color(r, g, b int) int {
+	nearestStep := func(value int) int {
+		best := 0
+		for i, step := range xterm256Steps {
+			if abs(step-value) < abs(xterm256Steps[best]-value) {
+				best = i
+			}
+		}
+		return best
+	}
+	distance := func(r2, g2, b2 int) int {
+		return (r-r2)*(r-r2) + (g-g2)*(g-g2) + (b-b2)*(b-b2)
+	}
+
+	ri, gi, bi := nearestStep(r), nearestStep(g), nearestStep(b)
+	index := 16 + 36*ri + 6*gi + bi
+	best := distance(xterm256Steps[ri], xterm256Steps[gi], xterm256Steps[bi])
+
+	grey := clampInt(((r+g+b)/3-8+5)/10, 0, 23)
+	if value := 8 + 10*grey; distance(value, value, value) < best {
+		index = 232 + grey
+	}
+	return index
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+// detectTerminalColor picks the richest colour mode out supports, honouring NO_COLOR.
+func detectTerminalColor(out io.Writer) string {
+	file, ok := out.(*os.File)
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return terminalColorNone
+	}
+	if info, err := file.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return terminalColorNone
+	}
+	if colorTerm := strings.ToLower(os.Getenv("COLORTERM")); colorTerm == "truecolor" || colorTerm == "24bit" {
+		return terminalColorTrueColor
+	}
+	if term := os.Getenv("TERM"); term == "" || term == "dumb" {
+		return terminalColorNone
+	}
+	return terminalColor256
+}
+
+// calendarPlanSummary describes what generating the contributions would create.
+func calendarPlanSummary(contributions []ContributionDay) string {
+	commits, days := 0, 0
+	first, last, busiest, most := "", "", "", 0
+	for _, day := range contributions {
+		if day.Count <= 0 {
+			continue
+		}
+		commits += day.Count
+		days++
+		if first == "" || day.Date < first {
+			first = day.Date
+		}
+		if day.Date > last {
+			last = day.Date
+		}
+		if day.Count > most || day.Count == most && day.Date < busiest {
+			busiest, most = day.Date, day.Count
+		}
+	}
+	if commits == 0 {
+		return "Plan: no commits to generate\n"
+	}
+	return fmt.Sprintf("Plan: %d commits on %d days from %s to %s; the busiest day is %s with %d commits\n", commits, days, first, last, busiest, most)
+}
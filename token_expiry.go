@@ -0,0 +1,202 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+const (
+	githubTokenExpiryEvent = "github:token-expiry"
+	// tokenExpiryHeader carries the expiry of fine-grained and expiring OAuth tokens.
+	tokenExpiryHeader = "github-authentication-token-expiration"
+
+	tokenRevalidateInterval = 30 * time.Minute
+	tokenExpiryWarning      = 7 * 24 * time.Hour
+)
+
+// errTokenRejected is returned when GitHub answers 401: the token was revoked or has expired.
+var errTokenRejected = errors.New("token invalid or expired")
+
+// TokenExpiryEvent is emitted through githubTokenExpiryEvent when an account's token is
+// about to expire or GitHub has started rejecting it.
+type TokenExpiryEvent struct {
+	AccountID string     `json:"accountId"`
+	Login     string     `json:"login"`
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"`
+	Expired   bool       `json:"expired"`
+}
+
+// parseTokenExpiry reads the expiry header, e.g. "2024-06-30 12:00:00 UTC" or
+// "2024-06-30 05:00:00 -0700".
+func parseTokenExpiry(header string) (time.Time, bool) {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700", time.RFC3339} {
+		if t, err := time.Parse(layout, header); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// observeTokenResponse records what a GitHub response says about the token that was sent:
+// its expiry date, or that it is no longer accepted.
+func (a *App) observeTokenResponse(req *http.Request, resp *http.Response) {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		return
+	}
+	expiry, hasExpiry := parseTokenExpiry(resp.Header.Get(tokenExpiryHeader))
+	rejected := resp.StatusCode == http.StatusUnauthorized
+	if !hasExpiry && !rejected {
+		return
+	}
+
+	a.mu.Lock()
+	var acc *githubAccount
+	for _, candidate := range a.accounts {
+		if candidate.token == token {
+			acc = candidate
+			break
+		}
+	}
+	// Installation tokens last an hour and are renewed by activeGithubToken instead.
+	if acc == nil || acc.isApp() {
+		a.mu.Unlock()
+		return
+	}
+	if hasExpiry && (acc.ExpiresAt == nil || !acc.ExpiresAt.Equal(expiry)) {
+		acc.ExpiresAt = &expiry
+		acc.expiryWarned = false
+	}
+	newlyExpired := rejected && !acc.Expired
+	if newlyExpired {
+		acc.Expired = true
+	}
+	a.mu.Unlock()
+
+	if newlyExpired {
+		a.accountExpired(acc)
+	}
+}
+
+// accountExpired keeps the account and its stored token so the user can see which login
+// needs renewing, and tells the frontend about it.
+func (a *App) accountExpired(acc *githubAccount) {
+	a.auditAs(acc.ID, auditActionTokenExpired, "", nil, nil)
+	if a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "GitHub rejected the token of %s; sign in again to renew it", acc.ID)
+	}
+	if err := a.saveAccounts(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+	}
+	a.emitTokenExpiry(acc)
+	if a.session().AccountID == acc.ID {
+		a.emitGithubAuthChanged()
+	}
+}
+
+// watchGithubTokens revalidates the unlocked accounts in the background until ctx is done
+// and warns about tokens that expire within tokenExpiryWarning. Sessions restored offline
+// are retried more often so they are verified soon after the network returns.
+func (a *App) watchGithubTokens(ctx context.Context) {
+	a.warnExpiringTokens()
+	for {
+		interval := tokenRevalidateInterval
+		if a.hasUnverifiedAccounts() {
+			interval = offlineRetryInterval
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		a.revalidateGithubTokens()
+	}
+}
+
+func (a *App) revalidateGithubTokens() {
+	a.mu.Lock()
+	var accounts []*githubAccount
+	for _, acc := range a.accounts {
+		if acc.token != "" && !acc.Expired {
+			accounts = append(accounts, acc)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, acc := range accounts {
+		// Expiry dates and 401s are picked up by observeTokenResponse.
+		wasUnverified, err := a.verifyAccount(acc)
+		if err != nil && !errors.Is(err, errTokenRejected) && !wasUnverified && a.ctx != nil {
+			runtime.LogWarningf(a.ctx, "revalidate GitHub token of %s failed: %v", acc.ID, err)
+		}
+		if err == nil && wasUnverified {
+			if a.ctx != nil {
+				runtime.LogInfof(a.ctx, "GitHub session of %s verified", acc.ID)
+			}
+			if a.session().AccountID == acc.ID {
+				// verifyAccount inspected the permissions it couldn't while offline.
+				a.emitGithubAuthChanged()
+			}
+			if err := a.saveAccounts(); err != nil && a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+			}
+		}
+	}
+	a.warnExpiringTokens()
+}
+
+func (a *App) hasUnverifiedAccounts() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for _, acc := range a.accounts {
+		if acc.unverified != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *App) warnExpiringTokens() {
+	now := time.Now()
+	a.mu.Lock()
+	var expiring []*githubAccount
+	for _, acc := range a.accounts {
+		if !acc.Expired && !acc.expiryWarned && acc.ExpiresAt != nil && acc.ExpiresAt.Sub(now) < tokenExpiryWarning {
+			acc.expiryWarned = true
+			expiring = append(expiring, acc)
+		}
+	}
+	a.mu.Unlock()
+
+	for _, acc := range expiring {
+		a.emitTokenExpiry(acc)
+	}
+}
+
+func (a *App) emitTokenExpiry(acc *githubAccount) {
+	if a.ctx == nil {
+		return
+	}
+	a.mu.Lock()
+	event := TokenExpiryEvent{AccountID: acc.ID, Expired: acc.Expired}
+	if acc.ExpiresAt != nil {
+		expiresAt := *acc.ExpiresAt
+		event.ExpiresAt = &expiresAt
+	}
+	if acc.User != nil {
+		event.Login = acc.User.Login
+	}
+	a.mu.Unlock()
+	runtime.EventsEmit(a.ctx, githubTokenExpiryEvent, event)
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTokenExpiry(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		want   time.Time
+		wantOK bool
+	}{
+		{name: "utc", header: "2024-06-30 12:00:00 UTC", want: time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "negative offset", header: "2024-06-30 05:00:00 -0700", want: time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "positive offset", header: "2024-07-01 01:30:00 +0800", want: time.Date(2024, 6, 30, 17, 30, 0, 0, time.UTC), wantOK: true},
+		{name: "surrounding space", header: "  2024-06-30 12:00:00 UTC\t", want: time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "rfc3339", header: "2024-06-30T12:00:00Z", want: time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC), wantOK: true},
+		{name: "empty", header: ""},
+		{name: "blank", header: "   "},
+		{name: "garbage", header: "never"},
+		{name: "date only", header: "2024-06-30"},
+		{name: "invalid day", header: "2024-02-30 12:00:00 UTC"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseTokenExpiry(tt.header)
+			if ok != tt.wantOK || !got.Equal(tt.want) {
+				t.Fatalf("parseTokenExpiry(%q) = %v, %v, want %v, %v", tt.header, got, ok, tt.want, tt.wantOK)
+			}
+			if ok && got.Location() != time.UTC {
+				t.Errorf("parseTokenExpiry(%q) is in %v, want UTC", tt.header, got.Location())
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// Token types reported in TokenPermissions.
+const (
+	tokenTypeClassic      = "classic"
+	tokenTypeOAuth        = "oauth"
+	tokenTypeFineGrained  = "fine-grained"
+	tokenTypeInstallation = "installation"
+	tokenTypeUnknown      = "unknown"
+)
+
+// TokenPermissions describes what the active token may do, as far as GitHub lets us find out.
+// Classic and OAuth tokens list their scopes in X-OAuth-Scopes; fine-grained tokens are probed.
+type TokenPermissions struct {
+	TokenType            string   `json:"tokenType"`
+	Scopes               []string `json:"scopes,omitempty"`
+	CanCreateRepo        bool     `json:"canCreateRepo"`
+	CanCreatePrivateRepo bool     `json:"canCreatePrivateRepo"`
+	CanPush              bool     `json:"canPush"`
+	CanDeleteRepo        bool     `json:"canDeleteRepo"`
+	Verified             bool     `json:"verified"`       // false when some permissions could not be determined
+	Note                 string   `json:"note,omitempty"` // what could not be determined, and why
+
+	creationProbed bool // probeRepoCreation answered, so CanCreateRepo is known
+}
+
+func cloneTokenPermissions(p *TokenPermissions) *TokenPermissions {
+	if p == nil {
+		return nil
+	}
+	clone := *p
+	clone.Scopes = append([]string(nil), p.Scopes...)
+	return &clone
+}
+
+// checkRemoteGeneration explains why the token can't create and push a repository, if it can't.
+// Unverified permissions never block generation; GitHub will have the final word. Fine-grained
+// tokens are never fully verified, but a creation probe that answered still counts.
+func (p *TokenPermissions) checkRemoteGeneration(private bool) error {
+	if p == nil {
+		return nil
+	}
+	if p.TokenType == tokenTypeFineGrained && p.creationProbed && !p.CanCreateRepo {
+		return fmt.Errorf("the fine-grained GitHub token cannot create repositories: grant \"Administration: Read and write\" for all repositories")
+	}
+	if !p.Verified {
+		return nil
+	}
+
+	switch p.TokenType {
+	case tokenTypeClassic, tokenTypeOAuth:
+		if !p.CanCreateRepo {
+			return fmt.Errorf("the GitHub token cannot create repositories: add the \"repo\" scope (or \"public_repo\" for public repositories) and sign in again")
+		}
+		if private && !p.CanCreatePrivateRepo {
+			return fmt.Errorf("the GitHub token only has the \"public_repo\" scope and cannot create private repositories: add the \"repo\" scope or make the repository public")
+		}
+	case tokenTypeInstallation:
+		if !p.CanCreateRepo {
+			return fmt.Errorf("the GitHub App installation cannot create repositories: grant the app \"Administration: Read and write\"")
+		}
+	}
+	if !p.CanPush {
+		return fmt.Errorf("the GitHub token cannot push commits: grant \"Contents: Read and write\"")
+	}
+	return nil
+}
+
+// tokenPermissions inspects a user token from the headers of its /user response, logging
+// what could not be found out.
+func (a *App) tokenPermissions(host, token string, header http.Header) *TokenPermissions {
+	permissions, err := a.inspectTokenPermissions(host, token, header)
+	if err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "inspect GitHub token permissions failed: %v", err)
+	}
+	return permissions
+}
+
+// inspectTokenPermissions works out what a user token may do from the headers GitHub sent
+// with its /user response, probing fine-grained tokens, which have no scopes to list.
+func (a *App) inspectTokenPermissions(host, token string, header http.Header) (*TokenPermissions, error) {
+	permissions := &TokenPermissions{TokenType: classifyToken(token, header)}
+	if header, ok := header["X-Oauth-Scopes"]; ok {
+		permissions.Scopes = parseOAuthScopes(strings.Join(header, ","))
+		permissions.applyScopes()
+		permissions.Verified = true
+		return permissions, nil
+	}
+
+	if permissions.TokenType == tokenTypeFineGrained {
+		// Contents access can't be probed without a repository, so the token stays unverified.
+		permissions.Note = "push access of fine-grained tokens can't be checked before the repository exists; GitHub refuses the push if the token lacks \"Contents: Read and write\""
+		canCreate, err := a.probeRepoCreation(host, token)
+		if err != nil {
+			return permissions, err
+		}
+		// Fine-grained tokens with Administration write may create private and public
+		// repositories alike.
+		permissions.CanCreateRepo = canCreate
+		permissions.CanCreatePrivateRepo = canCreate
+		permissions.CanDeleteRepo = canCreate
+		permissions.creationProbed = true
+	}
+	return permissions, nil
+}
+
+// probeRepoCreation asks GitHub to create a repository with an empty name. That can never
+// succeed, but tokens allowed to create repositories get a validation error (422) while the
+// others are turned away with 403 (404 on some Enterprise Server versions).
+//
+// The probe relies on GitHub checking permissions before validating the payload. Should a
+// future API validate first, every token would get 422 and look allowed; checkRemoteGeneration
+// would then let the generation through and GitHub's refusal of the real request would be
+// reported instead. Any other status is an error, leaving the permission unknown.
+func (a *App) probeRepoCreation(host, token string) (bool, error) {
+	req, err := a.newGithubRequestForHost(host, http.MethodPost, "/user/repos", token, map[string]interface{}{"name": ""})
+	if err != nil {
+		return false, err
+	}
+	resp, err := a.doGithubRequest(req)
+	if err != nil {
+		return false, fmt.Errorf("probe repository creation failed: %w", err)
+	}
+	resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusUnprocessableEntity:
+		return true, nil
+	case http.StatusForbidden, http.StatusNotFound:
+		return false, nil
+	default:
+		return false, fmt.Errorf("unexpected status %d while probing repository creation", resp.StatusCode)
+	}
+}
+
+func (p *TokenPermissions) applyScopes() {
+	has := func(scope string) bool { return containsString(p.Scopes, scope) }
+	p.CanCreatePrivateRepo = has("repo")
+	p.CanCreateRepo = p.CanCreatePrivateRepo || has("public_repo")
+	p.CanPush = p.CanCreateRepo
+	p.CanDeleteRepo = has("delete_repo")
+}
+
+func classifyToken(token string, header http.Header) string {
+	switch {
+	case strings.HasPrefix(token, "github_pat_"):
+		return tokenTypeFineGrained
+	case strings.HasPrefix(token, "ghp_"):
+		return tokenTypeClassic
+	case strings.HasPrefix(token, "gho_"):
+		return tokenTypeOAuth
+	}
+	if _, ok := header["X-Oauth-Scopes"]; ok {
+		return tokenTypeClassic
+	}
+	return tokenTypeUnknown
+}
+
+func parseOAuthScopes(header string) []string {
+	var scopes []string
+	for _, scope := range strings.Split(header, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
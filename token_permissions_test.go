@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSignInInspectsTokenPermissions(t *testing.T) {
+	tests := []struct {
+		name         string
+		token        string
+		scopes       string // X-OAuth-Scopes; empty leaves the header out
+		probeStatus  int
+		wantProbes   int32
+		wantVerified bool
+		wantCreate   bool
+		wantPush     bool
+		wantNote     bool
+		wantBlocked  bool
+	}{
+		{
+			name:         "classic token with repo scope",
+			token:        "ghp_classic",
+			scopes:       "repo, delete_repo",
+			wantVerified: true,
+			wantCreate:   true,
+			wantPush:     true,
+		},
+		{
+			name:         "classic token without repo scope",
+			token:        "ghp_classic",
+			scopes:       "read:user",
+			wantVerified: true,
+			wantBlocked:  true,
+		},
+		{
+			name:        "fine-grained token allowed to create repositories",
+			token:       "github_pat_allowed",
+			probeStatus: http.StatusUnprocessableEntity,
+			wantProbes:  1,
+			wantCreate:  true,
+			wantNote:    true,
+		},
+		{
+			name:        "fine-grained token without administration access",
+			token:       "github_pat_denied",
+			probeStatus: http.StatusForbidden,
+			wantProbes:  1,
+			wantNote:    true,
+			wantBlocked: true,
+		},
+		{
+			name:        "fine-grained token whose probe failed",
+			token:       "github_pat_unknown",
+			probeStatus: http.StatusBadGateway,
+			wantProbes:  1,
+			wantNote:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var userCalls, probes int32
+			mux := http.NewServeMux()
+			mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&userCalls, 1)
+				if tt.scopes != "" {
+					w.Header().Set("X-OAuth-Scopes", tt.scopes)
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"id": 1, "login": "octocat", "email": "octocat@example.com"})
+			})
+			mux.HandleFunc("/user/repos", func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&probes, 1)
+				w.WriteHeader(tt.probeStatus)
+			})
+			app, _ := newTestApp(t, mux)
+
+			if _, err := app.signInWithToken(defaultGithubHost, tt.token, false, ""); err != nil {
+				t.Fatal(err)
+			}
+			if n := atomic.LoadInt32(&userCalls); n != 1 {
+				t.Errorf("/user requested %d times, want once", n)
+			}
+			if n := atomic.LoadInt32(&probes); n != tt.wantProbes {
+				t.Errorf("creation probed %d times, want %d", n, tt.wantProbes)
+			}
+
+			p := app.session().Permissions
+			if p == nil {
+				t.Fatal("no permissions recorded")
+			}
+			if p.Verified != tt.wantVerified || p.CanCreateRepo != tt.wantCreate || p.CanPush != tt.wantPush || (p.Note != "") != tt.wantNote {
+				t.Errorf("permissions = %+v", p)
+			}
+			if err := p.checkRemoteGeneration(false); (err != nil) != tt.wantBlocked {
+				t.Errorf("checkRemoteGeneration() = %v, want blocked %v", err, tt.wantBlocked)
+			}
+		})
+	}
+}
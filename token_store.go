@@ -0,0 +1,555 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Storage backends for the remembered GitHub token.
+const (
+	tokenBackendKeyring    = "keyring"    // OS secret service; the token file only references the entry
+	tokenBackendPassphrase = "passphrase" // scrypt-derived key + AES-256-GCM, sealed into the token file
+)
+
+const (
+	tokenEnvelopeVersion = 1
+	keyringService       = "green-wall"
+	keyringTokenAccount  = "github_token"
+	scryptN              = 1 << 15
+	scryptR              = 8
+	scryptP              = 1
+	scryptKeyLen         = 32
+)
+
+var (
+	errTokenLocked       = errors.New("saved GitHub token is encrypted with a passphrase")
+	errWrongPassphrase   = errors.New("wrong passphrase for the saved GitHub token")
+	errKeyringItemAbsent = errors.New("no such entry in the OS secret service")
+)
+
+// tokenEnvelope holds a remembered token in accounts.json. The single-account token file
+// of older versions held one too, or before that the raw token, recognised by not being JSON.
+type tokenEnvelope struct {
+	Version    int    `json:"version"`
+	Backend    string `json:"backend"`
+	Account    string `json:"account,omitempty"`
+	KDF        string `json:"kdf,omitempty"`
+	N          int    `json:"n,omitempty"`
+	R          int    `json:"r,omitempty"`
+	P          int    `json:"p,omitempty"`
+	Salt       []byte `json:"salt,omitempty"`
+	Nonce      []byte `json:"nonce,omitempty"`
+	Ciphertext []byte `json:"ciphertext,omitempty"`
+}
+
+type TokenStorageStatus struct {
+	KeyringAvailable bool   `json:"keyringAvailable"`
+	Backend          string `json:"backend,omitempty"` // empty when nothing is stored
+	Locked           bool   `json:"locked"`            // a passphrase is needed to restore the login
+	NeedsMigration   bool   `json:"needsMigration"`    // a plaintext token from an older version is still on disk
+	Warning          string `json:"warning,omitempty"`
+}
+
+type TokenStorageRequest struct {
+	Backend    string `json:"backend"`
+	Passphrase string `json:"passphrase"`
+}
+
+type UnlockTokenRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// GetTokenStorageStatus reports how the active account's token is stored.
+func (a *App) GetTokenStorageStatus() (*TokenStorageStatus, error) {
+	status := &TokenStorageStatus{KeyringAvailable: keyringAvailable()}
+	a.mu.Lock()
+	if acc := a.activeAccount(); acc != nil && acc.Token != nil {
+		status.Backend = acc.Token.Backend
+	}
+	for _, acc := range a.accounts {
+		if acc.locked() {
+			status.Locked = true
+		}
+		if acc.legacyPlaintext {
+			status.NeedsMigration = true
+		}
+	}
+	a.mu.Unlock()
+	if status.NeedsMigration {
+		status.Warning = "Your GitHub token is still stored in plaintext by an older version. Choose a passphrase to encrypt it; the plaintext file is deleted once it is."
+	}
+	return status, nil
+}
+
+// ConfigureTokenStorage re-saves the active account's token with the chosen backend.
+func (a *App) ConfigureTokenStorage(req TokenStorageRequest) error {
+	a.mu.Lock()
+	acc := a.activeAccount()
+	usable := acc != nil && acc.token != ""
+	isApp := usable && acc.isApp()
+	a.mu.Unlock()
+	if !usable {
+		return fmt.Errorf("GitHub login is required to change token storage")
+	}
+	if isApp {
+		return fmt.Errorf("GitHub App accounts don't store a token; only the private key path is remembered")
+	}
+
+	switch req.Backend {
+	case tokenBackendKeyring:
+		if !keyringAvailable() {
+			return fmt.Errorf("no OS secret service is available on this system")
+		}
+		req.Passphrase = ""
+	case tokenBackendPassphrase:
+		if req.Passphrase == "" {
+			return fmt.Errorf("passphrase cannot be empty")
+		}
+	default:
+		return fmt.Errorf("unknown token storage backend %q", req.Backend)
+	}
+
+	if err := a.sealAccountToken(acc, req.Passphrase); err != nil {
+		return err
+	}
+	if err := a.saveAccounts(); err != nil {
+		return err
+	}
+	a.mu.Lock()
+	legacyPlaintext := acc.legacyPlaintext
+	acc.legacyPlaintext = false
+	a.mu.Unlock()
+	if legacyPlaintext {
+		return a.removeLegacyTokenFile()
+	}
+	return nil
+}
+
+// UnlockGithubToken decrypts every passphrase-protected account the passphrase opens. It
+// unlocks all of them or none: if GitHub rejects one, the others are locked again.
+func (a *App) UnlockGithubToken(req UnlockTokenRequest) (*GithubAuthResponse, error) {
+	a.mu.Lock()
+	var locked []*githubAccount
+	var envelopes []*tokenEnvelope
+	for _, acc := range a.accounts {
+		if acc.locked() {
+			locked = append(locked, acc)
+			envelopes = append(envelopes, acc.Token)
+		}
+	}
+	a.mu.Unlock()
+
+	var unlocked []*githubAccount
+	for i, acc := range locked {
+		token, err := openWithPassphrase(envelopes[i], req.Passphrase)
+		if err != nil {
+			continue
+		}
+		a.mu.Lock()
+		acc.token = token
+		a.mu.Unlock()
+		unlocked = append(unlocked, acc)
+		if _, err := a.verifyAccount(acc); err != nil {
+			if expired, unverified := a.accountState(acc); !expired && !unverified {
+				a.mu.Lock()
+				for _, u := range unlocked {
+					u.token = ""
+				}
+				a.mu.Unlock()
+				return nil, fmt.Errorf("unlocking %s: %w", acc.ID, err)
+			}
+		}
+	}
+	if len(unlocked) == 0 {
+		return nil, errWrongPassphrase
+	}
+
+	// Tokens migrated from the single-account file only learn their login now. Account IDs
+	// never change, so the migrated account replaces the placeholder.
+	a.mu.Lock()
+	for i, acc := range unlocked {
+		if acc.ID != legacyAccountID || acc.User == nil {
+			continue
+		}
+		id := githubAccountID(acc.Host, acc.User.Login)
+		if existing := a.findAccount(id); existing != nil {
+			existing.token, existing.User, existing.Token = acc.token, acc.User, acc.Token
+			existing.Expired, existing.unverified, existing.avatarData = acc.Expired, acc.unverified, acc.avatarData
+			a.dropAccount(acc)
+			unlocked[i] = existing
+			continue
+		}
+		migrated := *acc
+		migrated.ID = id
+		a.dropAccount(acc)
+		a.accounts = append(a.accounts, &migrated)
+		unlocked[i] = &migrated
+	}
+
+	active := a.activeAccount()
+	if active == nil || active.locked() {
+		active = unlocked[0]
+	}
+	a.mu.Unlock()
+	a.activateAccount(active)
+	if err := a.saveAccounts(); err != nil && a.ctx != nil {
+		runtime.LogWarningf(a.ctx, "failed to save GitHub accounts: %v", err)
+	}
+
+	a.mu.Lock()
+	user := active.profile()
+	a.mu.Unlock()
+	return &GithubAuthResponse{
+		AccountID:  active.ID,
+		User:       user,
+		Remembered: true,
+	}, nil
+}
+
+// sealAccountToken stores the account's token encrypted with the passphrase, or in the
+// OS secret service when no passphrase is given. Encryption and the secret service run
+// without a.mu.
+func (a *App) sealAccountToken(acc *githubAccount, passphrase string) error {
+	a.mu.Lock()
+	token, previous := acc.token, acc.Token
+	a.mu.Unlock()
+
+	var envelope *tokenEnvelope
+	if passphrase != "" {
+		sealed, err := sealWithPassphrase(token, passphrase)
+		if err != nil {
+			return err
+		}
+		// Don't leave a stale copy behind when switching away from the keyring.
+		if previous != nil && previous.Backend == tokenBackendKeyring && keyringAvailable() {
+			_ = keyringDelete(previous.Account)
+		}
+		envelope = sealed
+	} else {
+		if !keyringAvailable() {
+			return fmt.Errorf("no OS secret service is available; choose a passphrase to remember the token")
+		}
+		if err := keyringSet(acc.keyringAccount(), token); err != nil {
+			return fmt.Errorf("store token in OS secret service: %w", err)
+		}
+		envelope = &tokenEnvelope{Version: tokenEnvelopeVersion, Backend: tokenBackendKeyring, Account: acc.keyringAccount()}
+	}
+
+	a.mu.Lock()
+	acc.Token = envelope
+	a.mu.Unlock()
+	return nil
+}
+
+// forgetAccountToken drops the stored copy of the account's token, keeping the session.
+func (a *App) forgetAccountToken(acc *githubAccount) error {
+	a.mu.Lock()
+	envelope := acc.Token
+	acc.Token = nil
+	a.mu.Unlock()
+	if envelope != nil && envelope.Backend == tokenBackendKeyring {
+		if err := keyringDelete(envelope.Account); err != nil && !errors.Is(err, errKeyringItemAbsent) {
+			return fmt.Errorf("remove token from OS secret service: %w", err)
+		}
+	}
+	return nil
+}
+
+// loadGithubAccounts restores the remembered accounts and the one that was active.
+func (a *App) loadGithubAccounts() error {
+	file, err := a.readAccountsFile()
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	changed := false
+	for _, acc := range file.Accounts {
+		if acc.isApp() {
+			acc.remembered = true
+			a.addAccount(acc)
+			if _, err := a.verifyAccount(acc); err != nil && firstErr == nil {
+				if expired, unverified := a.accountState(acc); !expired && !unverified {
+					firstErr = err
+				}
+			}
+			continue
+		}
+		if acc.Token == nil {
+			continue
+		}
+		token, err := openEnvelope(acc.Token, "")
+		if errors.Is(err, errTokenLocked) {
+			// Wait for UnlockGithubToken; the UI learns about it from GetTokenStorageStatus.
+			a.addAccount(acc)
+			continue
+		}
+		if err == nil {
+			// Registered before the lookup so the response's expiry header is recorded.
+			wasExpired := acc.Expired
+			acc.token = token
+			a.addAccount(acc)
+			_, err = a.verifyAccount(acc)
+			expired, unverified := a.accountState(acc)
+			if expired != wasExpired {
+				changed = true
+			}
+			// Rejected tokens are kept so the user knows which login to renew, and
+			// sessions restored offline are revalidated once GitHub is reachable.
+			if err == nil || expired || unverified {
+				continue
+			}
+			a.mu.Lock()
+			a.dropAccount(acc)
+			a.mu.Unlock()
+		}
+		_ = a.forgetAccountToken(acc)
+		changed = true
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	if err := a.migrateLegacyToken(); err != nil && firstErr == nil {
+		firstErr = err
+	}
+
+	a.mu.Lock()
+	active := a.findAccount(file.ActiveAccountID)
+	if active == nil || active.locked() {
+		active = nil
+		for _, acc := range a.accounts {
+			if !acc.locked() {
+				active = acc
+				break
+			}
+		}
+	}
+	a.mu.Unlock()
+	if active != nil {
+		a.activateAccount(active)
+	}
+	if changed {
+		if err := a.saveAccounts(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}
+
+// legacyAccountID stands in for the login of a passphrase-protected token migrated from
+// the single-account token file until the passphrase is entered.
+const legacyAccountID = "legacy"
+
+// migrateLegacyToken moves the single-account token file written by older versions into accounts.json.
+func (a *App) migrateLegacyToken() error {
+	data, err := a.readTokenFile()
+	if err != nil || data == nil {
+		return err
+	}
+	envelope, legacy, err := parseTokenEnvelope(data)
+	if err != nil {
+		return err
+	}
+
+	if !legacy && envelope.Backend == tokenBackendPassphrase {
+		a.addAccount(&githubAccount{ID: legacyAccountID, Host: defaultGithubHost, Token: envelope})
+		if err := a.saveAccounts(); err != nil {
+			return err
+		}
+		return a.removeLegacyTokenFile()
+	}
+
+	token, err := openStoredToken(data, "")
+	if err != nil || token == "" {
+		return err
+	}
+	user, header, err := a.fetchGithubUser(defaultGithubHost, token)
+	if err != nil {
+		// Keep the file while offline; it is migrated on the next start.
+		if errors.Is(err, errTokenRejected) {
+			_ = a.removeLegacyTokenFile()
+		}
+		return err
+	}
+
+	permissions := a.tokenPermissions(defaultGithubHost, token, header)
+	keyring := keyringAvailable()
+	a.mu.Lock()
+	acc := a.upsertAccount(defaultGithubHost, user)
+	if legacy && acc.Token != nil {
+		a.mu.Unlock()
+		// The account is already remembered securely, so the plaintext copy is just a leftover.
+		return a.removeLegacyTokenFile()
+	}
+	acc.token = token
+	acc.permissions = permissions
+	if !legacy {
+		acc.Token = envelope
+	}
+	plaintext := legacy && !keyring
+	acc.legacyPlaintext = plaintext
+	a.mu.Unlock()
+	if legacy {
+		// Plaintext tokens move into the OS secret service. Without one the file stays until
+		// the user picks a passphrase, which the UI asks for on every start meanwhile.
+		if plaintext {
+			if a.ctx != nil {
+				runtime.LogWarningf(a.ctx, "The GitHub token is still stored in plaintext; choose a passphrase to encrypt it")
+			}
+			return nil
+		}
+		if err := a.sealAccountToken(acc, ""); err != nil {
+			return fmt.Errorf("migrate saved GitHub token: %w", err)
+		}
+	}
+	if err := a.saveAccounts(); err != nil {
+		return err
+	}
+	return a.removeLegacyTokenFile()
+}
+
+func (a *App) removeLegacyTokenFile() error {
+	path, err := a.tokenStoragePath()
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (a *App) readTokenFile() ([]byte, error) {
+	path, err := a.tokenStoragePath()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return nil, nil
+	}
+	return data, nil
+}
+
+// parseTokenEnvelope decodes the token file; legacy reports a plaintext file from an older version.
+func parseTokenEnvelope(data []byte) (*tokenEnvelope, bool, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return nil, true, nil
+	}
+
+	var envelope tokenEnvelope
+	if err := json.Unmarshal(trimmed, &envelope); err != nil {
+		return nil, false, fmt.Errorf("decode saved GitHub token: %w", err)
+	}
+	if envelope.Version != tokenEnvelopeVersion {
+		return nil, false, fmt.Errorf("unsupported saved token version %d", envelope.Version)
+	}
+	return &envelope, false, nil
+}
+
+// openStoredToken returns the token held by the single-account token file of older versions.
+func openStoredToken(data []byte, passphrase string) (string, error) {
+	envelope, legacy, err := parseTokenEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	if legacy {
+		return strings.TrimSpace(string(data)), nil
+	}
+	return openEnvelope(envelope, passphrase)
+}
+
+// openEnvelope resolves keyring references and decrypts passphrase envelopes.
+// errTokenLocked is returned when a passphrase is needed but none was given.
+func openEnvelope(envelope *tokenEnvelope, passphrase string) (string, error) {
+	switch envelope.Backend {
+	case tokenBackendKeyring:
+		token, err := keyringGet(envelope.Account)
+		if err != nil {
+			return "", fmt.Errorf("read token from OS secret service: %w", err)
+		}
+		return token, nil
+	case tokenBackendPassphrase:
+		if passphrase == "" {
+			return "", errTokenLocked
+		}
+		return openWithPassphrase(envelope, passphrase)
+	default:
+		return "", fmt.Errorf("unknown token storage backend %q", envelope.Backend)
+	}
+}
+
+func sealWithPassphrase(secret, passphrase string) (*tokenEnvelope, error) {
+	envelope := &tokenEnvelope{
+		Version: tokenEnvelopeVersion,
+		Backend: tokenBackendPassphrase,
+		KDF:     "scrypt",
+		N:       scryptN,
+		R:       scryptR,
+		P:       scryptP,
+		Salt:    make([]byte, 16),
+	}
+	if _, err := rand.Read(envelope.Salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+
+	gcm, err := envelopeCipher(envelope, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	envelope.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(envelope.Nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	envelope.Ciphertext = gcm.Seal(nil, envelope.Nonce, []byte(secret), nil)
+	return envelope, nil
+}
+
+func openWithPassphrase(envelope *tokenEnvelope, passphrase string) (string, error) {
+	if envelope.KDF != "scrypt" {
+		return "", fmt.Errorf("unsupported key derivation %q", envelope.KDF)
+	}
+	gcm, err := envelopeCipher(envelope, passphrase)
+	if err != nil {
+		return "", err
+	}
+	if len(envelope.Nonce) != gcm.NonceSize() {
+		return "", fmt.Errorf("saved GitHub token is corrupted")
+	}
+	plain, err := gcm.Open(nil, envelope.Nonce, envelope.Ciphertext, nil)
+	if err != nil {
+		return "", errWrongPassphrase
+	}
+	return string(plain), nil
+}
+
+func envelopeCipher(envelope *tokenEnvelope, passphrase string) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), envelope.Salt, envelope.N, envelope.R, envelope.P, scryptKeyLen)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestSealAndOpenWithPassphrase(t *testing.T) {
+	envelope, err := sealWithPassphrase("ghp_secret", "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if envelope.Backend != tokenBackendPassphrase || envelope.KDF != "scrypt" {
+		t.Fatalf("envelope = %+v, want a scrypt passphrase envelope", envelope)
+	}
+	if strings.Contains(string(envelope.Ciphertext), "ghp_secret") {
+		t.Fatal("the ciphertext contains the token")
+	}
+
+	token, err := openWithPassphrase(envelope, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghp_secret" {
+		t.Errorf("openWithPassphrase() = %q, want %q", token, "ghp_secret")
+	}
+	if _, err := openWithPassphrase(envelope, "wrong horse"); !errors.Is(err, errWrongPassphrase) {
+		t.Errorf("openWithPassphrase() with the wrong passphrase = %v, want %v", err, errWrongPassphrase)
+	}
+}
+
+// lockedAccount returns an account whose token is sealed with the passphrase and not yet opened.
+func lockedAccount(t *testing.T, id, token, passphrase string) *githubAccount {
+	t.Helper()
+	envelope, err := sealWithPassphrase(token, passphrase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &githubAccount{ID: id, Host: defaultGithubHost, Token: envelope}
+}
+
+func TestUnlockGithubTokenWrongPassphrase(t *testing.T) {
+	app, _ := newTestApp(t, http.NotFoundHandler())
+	acc := lockedAccount(t, "github.com/octocat", "ghp_octocat", "passphrase")
+	app.addAccount(acc)
+
+	if _, err := app.UnlockGithubToken(UnlockTokenRequest{Passphrase: "guess"}); !errors.Is(err, errWrongPassphrase) {
+		t.Fatalf("UnlockGithubToken() = %v, want %v", err, errWrongPassphrase)
+	}
+	if !acc.locked() {
+		t.Error("the account was unlocked with the wrong passphrase")
+	}
+}
+
+func TestUnlockGithubTokenAllOrNone(t *testing.T) {
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") == "Bearer ghp_good" {
+			w.Write([]byte(`{"id":1,"login":"octocat","email":"octocat@example.com"}`))
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	good := lockedAccount(t, "good", "ghp_good", "passphrase")
+	bad := lockedAccount(t, "bad", "ghp_bad", "passphrase")
+	app.addAccount(good)
+	app.addAccount(bad)
+
+	_, err := app.UnlockGithubToken(UnlockTokenRequest{Passphrase: "passphrase"})
+	if err == nil || !strings.Contains(err.Error(), "bad") {
+		t.Fatalf("UnlockGithubToken() = %v, want an error naming the bad account", err)
+	}
+	for _, acc := range []*githubAccount{good, bad} {
+		if !acc.locked() {
+			t.Errorf("account %s is unlocked after a failed unlock", acc.ID)
+		}
+	}
+	if status := app.GetGithubLoginStatus(); status.Authenticated {
+		t.Errorf("GetGithubLoginStatus() = %+v, want no active session", status)
+	}
+}
+
+func TestMigratePlaintextTokenToPassphrase(t *testing.T) {
+	if keyringAvailable() {
+		t.Skip("plaintext tokens move into the OS secret service on this system")
+	}
+	app, _ := newTestApp(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/user" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"id":1,"login":"octocat","email":"octocat@example.com"}`))
+	}))
+	path, err := app.tokenStoragePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("ghp_plaintext\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.loadGithubAccounts(); err != nil {
+		t.Fatal(err)
+	}
+	status, _ := app.GetTokenStorageStatus()
+	if !status.NeedsMigration {
+		t.Fatalf("GetTokenStorageStatus() = %+v, want the plaintext token flagged", status)
+	}
+
+	req := TokenStorageRequest{Backend: tokenBackendPassphrase, Passphrase: "passphrase"}
+	if err := app.ConfigureTokenStorage(req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("the plaintext token file is still there: %v", err)
+	}
+	status, _ = app.GetTokenStorageStatus()
+	if status.NeedsMigration || status.Backend != tokenBackendPassphrase {
+		t.Errorf("GetTokenStorageStatus() = %+v, want a passphrase-sealed token", status)
+	}
+
+	file, err := app.readAccountsFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Accounts) != 1 || file.Accounts[0].Token == nil {
+		t.Fatalf("accounts.json holds %+v, want one account with a sealed token", file.Accounts)
+	}
+	token, err := openWithPassphrase(file.Accounts[0].Token, "passphrase")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "ghp_plaintext" {
+		t.Errorf("sealed token = %q, want %q", token, "ghp_plaintext")
+	}
+}